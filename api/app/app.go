@@ -0,0 +1,170 @@
+// Package app wires the repositories and services of the blogger app, so that
+// every command of the server, and not only the HTTP layer, works through the
+// same ones.
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "example.com/m/v2/db"
+	ioc "example.com/m/v2/ioc"
+	health "example.com/m/v2/pkg/health"
+	similarity "example.com/m/v2/pkg/similarity"
+	repositories "example.com/m/v2/repositories"
+	services "example.com/m/v2/services"
+	"gorm.io/gorm"
+)
+
+// How long the results of the health checks of the database are reused.
+const (
+	DatabaseCheckCacheFor  = 5 * time.Second
+	MigrationCheckCacheFor = time.Minute
+)
+
+// Backends of the repositories, selected by `database.backend`.
+const (
+	BackendPostgres = "postgres"
+	BackendMemory   = "memory"
+)
+
+type Options struct {
+	// Backend overrides `database.backend`, e.g. for commands that do not
+	// need the database.
+	Backend string
+
+	// AllowSchemaMismatch starts the app even if the schema is not at the
+	// version that this binary expects.
+	AllowSchemaMismatch bool
+}
+
+// App holds the dependencies shared by the commands.
+type App struct {
+	IOC *ioc.IOC
+
+	// DB is the primary database, or nil with the memory backend.
+	DB *gorm.DB
+
+	BlogRepository       repositories.BlogRepository
+	AttachmentRepository repositories.AttachmentRepository
+	BlogService          services.BlogService
+	AttachmentService    services.AttachmentService
+}
+
+// New connects to the backend named by `database.backend`: "postgres" (the
+// default), or "memory" to run without a database. Despite its name, the
+// "postgres" backend also runs on SQLite, given a `sqlite://` `database.url`.
+// Blogs are read from the replicas in `database.replica_urls`, if any.
+//
+// New fails unless the schema is at the version that it expects, or
+// AllowSchemaMismatch is set; an in-memory SQLite database is migrated first.
+// Background workers, such as the health checks of the replicas, are appended
+// to the lifecycle of c, and only run once it is started. The database and its
+// schema are checked by the readiness probe.
+func New(c *ioc.IOC, opts Options) (*App, error) {
+	a := &App{IOC: c}
+
+	backend := opts.Backend
+	if backend == "" {
+		backend = c.Config.Database.Backend
+	}
+	switch backend {
+	case BackendPostgres:
+		cluster, err := db.ConnectCluster(c, c.Config.Database)
+		if err != nil {
+			return nil, err
+		}
+		c.Lifecycle.Append(ioc.Hook{
+			Name: "database pools",
+			OnStop: func(context.Context) error {
+				return cluster.Close()
+			},
+		})
+		c.Lifecycle.Go("database health monitor", func(ctx context.Context) {
+			cluster.Monitor(ctx, db.DefaultHealthCheckInterval)
+		})
+		c.Health.Register(health.Check{
+			Name:     "database",
+			Probes:   []health.Probe{health.Ready},
+			CacheFor: DatabaseCheckCacheFor,
+			Run:      cluster.Ping,
+		})
+
+		a.DB = cluster.Primary()
+		migrator, err := NewMigrator(c, a.DB)
+		if err != nil {
+			return nil, err
+		}
+		if db.IsMemory(c.Config.Database.URL) {
+			// A throwaway database starts out empty, and no other process
+			// can migrate it.
+			if err := migrator.Up(); err != nil {
+				return nil, err
+			}
+		}
+		if err := migrator.CheckVersion(); err != nil {
+			if !opts.AllowSchemaMismatch {
+				return nil, err
+			}
+			c.Logger.Warn("Starting anyway, since -allow-schema-mismatch is set:", err)
+		} else {
+			// The schema may change under a running server. A newer version
+			// migrates it forward during a rolling deployment, which must not
+			// take the older replicas out of service; but a rollback of the
+			// schema must.
+			c.Health.Register(health.Check{
+				Name:     "migrations",
+				Probes:   []health.Probe{health.Startup, health.Ready},
+				CacheFor: MigrationCheckCacheFor,
+				Run:      migrator.CheckMigrated,
+			})
+		}
+
+		a.BlogRepository = newCachedBlogRepository(c, a.DB, repositories.NewPostgreSQLBlogRepository(c, a.DB).WithReplicas(cluster))
+		a.AttachmentRepository = repositories.NewPostgreSQLAttachmentRepository(c, a.DB)
+	case BackendMemory:
+		c.Logger.Warn("Using the in-memory repositories; data is lost on restart")
+		a.BlogRepository = repositories.NewMemoryBlogRepository()
+		a.AttachmentRepository = repositories.NewMemoryAttachmentRepository()
+	default:
+		return nil, fmt.Errorf("unknown backend %q: expected postgres or memory", backend)
+	}
+
+	a.BlogService = services.NewTracedBlogService(c, services.NewBlogService(c, similarity.NewIndex(), services.NewDuplicatePolicy(c.Config.Duplicates)))
+	a.AttachmentService = services.NewAttachmentService(c, c.Config.Attachments.MaxSize)
+
+	return a, nil
+}
+
+// newCachedBlogRepository caches the blogs read from r, as configured by
+// `cache.size` and `cache.ttl`. On PostgreSQL, the caches of all replicas are
+// invalidated together through `LISTEN`/`NOTIFY`.
+func newCachedBlogRepository(c *ioc.IOC, gormDB *gorm.DB, r repositories.BlogRepository) repositories.BlogRepository {
+	if c.Config.Cache.Size == 0 {
+		return r
+	}
+
+	var invalidator repositories.CacheInvalidator
+	dsn := c.Config.Database.URL
+	if dialect, _ := db.Dialect(dsn); dialect == db.DialectPostgres {
+		listener := repositories.NewPostgreSQLCacheInvalidator(c, gormDB, dsn, repositories.DefaultCacheChannel)
+		c.Lifecycle.Go("cache invalidation listener", listener.Listen)
+		invalidator = listener
+	}
+	config := c.Config.Cache
+	if len(c.Config.Database.ReplicaURLs) == 0 {
+		// Every read is from the primary, which has seen every write.
+		config.ReplicaLag = 0
+	}
+	return repositories.NewCachedBlogRepository(c, r, config, invalidator)
+}
+
+// NewMigrator returns the migrator for the dialect of `database.url`.
+func NewMigrator(c *ioc.IOC, gormDB *gorm.DB) (*db.Migrator, error) {
+	dialect, err := db.Dialect(c.Config.Database.URL)
+	if err != nil {
+		return nil, err
+	}
+	return db.NewMigrator(c, gormDB, dialect)
+}
@@ -0,0 +1,35 @@
+// errordocs writes the reference documentation of the error catalog, as
+// errors.md and errors.json, to a directory. It is run by `go generate`.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	apiErrors "example.com/m/v2/errors"
+)
+
+func main() {
+	out := flag.String("out", ".", "directory to write errors.md and errors.json to")
+	flag.Parse()
+
+	if err := write(*out); err != nil {
+		fmt.Fprintln(os.Stderr, "errordocs:", err)
+		os.Exit(1)
+	}
+}
+
+func write(dir string) error {
+	if err := os.WriteFile(filepath.Join(dir, "errors.md"), []byte(apiErrors.Markdown()), 0o644); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(apiErrors.Catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "errors.json"), append(b, '\n'), 0o644)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"errors"
+	"os"
+
+	db "example.com/m/v2/db"
+	ioc "example.com/m/v2/ioc"
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = "usage: server config print"
+
+// runConfig implements `server config print`, which prints the configuration
+// that the server would run with, in the format of the config file. DSNs are
+// redacted, as they may hold passwords.
+func runConfig(c *ioc.IOC, args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+
+	cfg := *c.Config
+	cfg.Database.URL = db.Redact(cfg.Database.URL)
+	cfg.Database.ReplicaURLs = make([]string, len(c.Config.Database.ReplicaURLs))
+	for i, dsn := range c.Config.Database.ReplicaURLs {
+		cfg.Database.ReplicaURLs[i] = db.Redact(dsn)
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	return enc.Encode(cfg)
+}
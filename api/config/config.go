@@ -0,0 +1,288 @@
+// Package config is the configuration of the server. It is loaded once on
+// startup from, in increasing order of precedence:
+//
+//  1. the defaults,
+//  2. a YAML file, named by the `-config` flag or `CONFIG_FILE`,
+//  3. environment variables, each of which may instead be read from the file
+//     named by the same variable suffixed with `_FILE`, such as a Kubernetes
+//     secret mounted as `DATABASE_URL_FILE`,
+//  4. flags, named by the YAML path of a setting, e.g. `-database.url`.
+//
+// The fields of Config declare where they are read from with their tags:
+// `yaml` names the key in the file and the flag, `env` lists the environment
+// variables, of which the first one set wins, and `usage` documents the flag.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Server      Server      `yaml:"server"`
+	Database    Database    `yaml:"database"`
+	Cache       Cache       `yaml:"cache"`
+	HTTPCache   HTTPCache   `yaml:"http_cache"`
+	Duplicates  Duplicates  `yaml:"duplicates"`
+	Attachments Attachments `yaml:"attachments"`
+	Tracing     Tracing     `yaml:"tracing"`
+}
+
+// Server configures the HTTP server. On SIGTERM, the server fails its
+// readiness check, and keeps serving for DrainPeriod so that load balancers
+// stop sending it requests. It then waits up to ShutdownTimeout for the
+// requests in flight, and for the other components to stop.
+type Server struct {
+	Port            int           `yaml:"port" env:"PORT" usage:"port to serve the API on"`
+	Mode            string        `yaml:"mode" env:"GIN_MODE" usage:"gin mode: debug, release or test"`
+	ExposeRoutes    bool          `yaml:"expose_routes" env:"SERVER_EXPOSE_ROUTES" usage:"serve the debugging endpoints /_routes and /_cache, and honour the X-Cache-Bypass header"`
+	ProblemDetails  bool          `yaml:"problem_details" env:"PROBLEM_DETAILS" usage:"render every error as RFC 7807 problem details, instead of only for clients that ask for them"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" usage:"how long reading a request, body included, may take"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" usage:"how long handling a request and writing its response may take"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" usage:"how long an idle keep-alive connection is kept open"`
+	DrainPeriod     time.Duration `yaml:"drain_period" env:"SERVER_DRAIN_PERIOD" usage:"how long to keep serving after failing readiness on shutdown"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" usage:"how long to wait for requests in flight and components to stop on shutdown"`
+}
+
+// Database configures the repositories, and the connection pool of each
+// database. ConnectTimeout is how long connecting retries for while the
+// database is unavailable.
+type Database struct {
+	Backend         string        `yaml:"backend" env:"REPOSITORY_BACKEND" usage:"repository backend: postgres, which also runs on SQLite, or memory"`
+	URL             string        `yaml:"url" env:"DATABASE_URL,POSTGRESQL_URL" usage:"DSN of the primary database, postgres:// or sqlite://"`
+	ReplicaURLs     []string      `yaml:"replica_urls" env:"DATABASE_REPLICA_URLS" usage:"DSNs of the read replicas, separated by commas"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT" usage:"how long to retry connecting to the database for"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"maximum number of open connections to each database"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"maximum number of idle connections to each database"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"how long a connection may be reused for"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" usage:"how long a connection may be idle for"`
+}
+
+// Cache sizes the cache of blogs. A Size of 0 disables it. Blogs read from the
+// replicas within ReplicaLag of a write are not cached, as they may predate it.
+type Cache struct {
+	Size       int           `yaml:"size" env:"BLOG_CACHE_SIZE" usage:"number of blogs to cache, or 0 to disable the cache"`
+	TTL        time.Duration `yaml:"ttl" env:"BLOG_CACHE_TTL" usage:"how long blogs are cached for"`
+	ReplicaLag time.Duration `yaml:"replica_lag" env:"BLOG_CACHE_REPLICA_LAG" usage:"how long after a write blogs read from the replicas are not cached"`
+}
+
+// HTTPCache sets the `Cache-Control` header sent by each cacheable blog route.
+// An empty value omits the header for that route.
+type HTTPCache struct {
+	Index string `yaml:"index" env:"HTTP_CACHE_INDEX" usage:"Cache-Control header of the list of blogs, or empty to omit it"`
+	Show  string `yaml:"show" env:"HTTP_CACHE_SHOW" usage:"Cache-Control header of a blog, or empty to omit it"`
+	Words string `yaml:"words" env:"HTTP_CACHE_WORDS" usage:"Cache-Control header of the word count of a blog, or empty to omit it"`
+}
+
+// Duplicates configures the detection of near-duplicate blogs. Threshold is
+// the SimHash similarity, from 0 to 1, at or above which two blogs are
+// near-duplicates.
+type Duplicates struct {
+	Threshold float64 `yaml:"threshold" env:"DUPLICATE_THRESHOLD" usage:"similarity from 0 to 1 at or above which blogs are near-duplicates"`
+	Action    string  `yaml:"action" env:"DUPLICATE_ACTION" usage:"what to do with near-duplicates: ignore, warn or reject"`
+}
+
+type Attachments struct {
+	StoragePath string `yaml:"storage_path" env:"ATTACHMENT_STORAGE_PATH" usage:"directory to store uploaded files in"`
+	MaxSize     int64  `yaml:"max_size" env:"ATTACHMENT_MAX_SIZE" usage:"largest file, in bytes, that may be attached to a blog"`
+}
+
+// Tracing configures the export of traces: to an OpenTelemetry collector over
+// OTLP/HTTP at Endpoint, or as JSON to stdout or File for local work. Requests
+// whose caller has not decided whether to sample them are sampled at
+// SampleRatio.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" usage:"where to export traces: none, otlp, stdout or file"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" usage:"base URL of the OTLP/HTTP collector, e.g. http://localhost:4318 (default from the OTLP exporter)"`
+	File        string  `yaml:"file" env:"TRACING_FILE" usage:"file that the file exporter appends traces to"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"fraction of traces, from 0 to 1, to sample"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" usage:"name of the service in traces"`
+}
+
+// Default returns the configuration used for every setting that is not set.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:            8080,
+			Mode:            "release",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			DrainPeriod:     5 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Database: Database{
+			Backend:         "postgres",
+			ConnectTimeout:  time.Minute,
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		Cache: Cache{
+			Size:       1000,
+			TTL:        30 * time.Second,
+			ReplicaLag: 5 * time.Second,
+		},
+		// Clients revalidate on every use. Since the reads emit an `ETag` and
+		// `Last-Modified`, revalidation is cheap (304).
+		HTTPCache: HTTPCache{
+			Index: "no-cache",
+			Show:  "no-cache",
+			Words: "no-cache",
+		},
+		Duplicates: Duplicates{
+			Threshold: 0.9,
+			Action:    "reject",
+		},
+		Attachments: Attachments{
+			StoragePath: "data/attachments",
+			MaxSize:     10 << 20,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			SampleRatio: 1,
+			ServiceName: "blogger",
+		},
+	}
+}
+
+// Load reads the configuration from the defaults, the YAML file (unless file
+// is empty), the environment, and then flags, a map of the YAML paths of
+// settings to their values. It fails unless the result is valid.
+func Load(file string, flags map[string]string) (*Config, error) {
+	c := Default()
+
+	if file != "" {
+		if err := c.readFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range fields(c) {
+		v, source, err := lookupEnv(f.env)
+		if err != nil {
+			return nil, err
+		}
+		if source == "" {
+			continue
+		}
+		if err := f.set(v); err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+
+	for _, f := range fields(c) {
+		if v, ok := flags[f.path]; ok {
+			if err := f.set(v); err != nil {
+				return nil, fmt.Errorf("-%s: %w", f.path, err)
+			}
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) readFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// lookupEnv returns the value of the first of names that is set, directly or
+// through its `_FILE` variant, along with where it was read from.
+func lookupEnv(names []string) (string, string, error) {
+	for _, name := range names {
+		v := os.Getenv(name)
+		file := os.Getenv(name + "_FILE")
+		switch {
+		case v != "" && file != "":
+			return "", "", fmt.Errorf("%s and %s_FILE cannot both be set", name, name)
+		case v != "":
+			return v, name, nil
+		case file != "":
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return "", "", fmt.Errorf("%s_FILE: %w", name, err)
+			}
+			return strings.TrimRight(string(b), "\r\n"), name + "_FILE", nil
+		}
+	}
+	return "", "", nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 1<<16, "server.port must be between 1 and 65535, got %d", c.Server.Port)
+	check(oneOf(c.Server.Mode, "debug", "release", "test"), "server.mode must be debug, release or test, got %q", c.Server.Mode)
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout must not be negative, got %s", c.Server.ReadTimeout)
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout must not be negative, got %s", c.Server.WriteTimeout)
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout must not be negative, got %s", c.Server.IdleTimeout)
+	check(c.Server.DrainPeriod >= 0, "server.drain_period must not be negative, got %s", c.Server.DrainPeriod)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive, got %s", c.Server.ShutdownTimeout)
+
+	check(oneOf(c.Database.Backend, "postgres", "memory"), "database.backend must be postgres or memory, got %q", c.Database.Backend)
+	check(c.Database.ConnectTimeout > 0, "database.connect_timeout must be positive, got %s", c.Database.ConnectTimeout)
+	check(c.Database.MaxOpenConns >= 0, "database.max_open_conns must not be negative, got %d", c.Database.MaxOpenConns)
+	check(c.Database.MaxIdleConns >= 0, "database.max_idle_conns must not be negative, got %d", c.Database.MaxIdleConns)
+	check(c.Database.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative, got %s", c.Database.ConnMaxLifetime)
+	check(c.Database.ConnMaxIdleTime >= 0, "database.conn_max_idle_time must not be negative, got %s", c.Database.ConnMaxIdleTime)
+
+	check(c.Cache.Size >= 0, "cache.size must not be negative, got %d", c.Cache.Size)
+	check(c.Cache.TTL > 0, "cache.ttl must be positive, got %s", c.Cache.TTL)
+	check(c.Cache.ReplicaLag >= 0, "cache.replica_lag must not be negative, got %s", c.Cache.ReplicaLag)
+
+	check(!strings.ContainsAny(c.HTTPCache.Index, "\r\n"), "http_cache.index must be a single line")
+	check(!strings.ContainsAny(c.HTTPCache.Show, "\r\n"), "http_cache.show must be a single line")
+	check(!strings.ContainsAny(c.HTTPCache.Words, "\r\n"), "http_cache.words must be a single line")
+
+	check(c.Duplicates.Threshold >= 0 && c.Duplicates.Threshold <= 1, "duplicates.threshold must be between 0 and 1, got %g", c.Duplicates.Threshold)
+	check(oneOf(c.Duplicates.Action, "ignore", "warn", "reject"), "duplicates.action must be ignore, warn or reject, got %q", c.Duplicates.Action)
+
+	check(c.Attachments.StoragePath != "", "attachments.storage_path must be set")
+	check(c.Attachments.MaxSize > 0, "attachments.max_size must be positive, got %d", c.Attachments.MaxSize)
+
+	check(oneOf(c.Tracing.Exporter, "none", "otlp", "stdout", "file"), "tracing.exporter must be none, otlp, stdout or file, got %q", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "file" || c.Tracing.File != "", "tracing.file must be set with the file exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	check(c.Tracing.ServiceName != "", "tracing.service_name must be set")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func oneOf(v string, values ...string) bool {
+	for _, value := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
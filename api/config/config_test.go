@@ -0,0 +1,107 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  mode: debug
+database:
+  url: postgres://db/blogger
+  max_open_conns: 10
+  conn_max_lifetime: 1h
+cache:
+  size: 50
+`)
+	t.Setenv("DB_MAX_OPEN_CONNS", "20")
+	t.Setenv("BLOG_CACHE_SIZE", "60")
+
+	c, err := Load(file, map[string]string{"cache.size": "70"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 9000, c.Server.Port, "The file overrides the defaults")
+	assert.Equal(t, "debug", c.Server.Mode)
+	assert.Equal(t, time.Hour, c.Database.ConnMaxLifetime)
+	assert.Equal(t, 20, c.Database.MaxOpenConns, "The environment overrides the file")
+	assert.Equal(t, 70, c.Cache.Size, "Flags override the environment")
+	assert.Equal(t, Default().Database.ConnectTimeout, c.Database.ConnectTimeout, "Unset settings keep their default")
+	assert.Equal(t, "release", Default().Server.Mode)
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("POSTGRESQL_URL", "postgres://legacy/blogger")
+	t.Setenv("DATABASE_REPLICA_URLS", "postgres://a/blogger, ,postgres://b/blogger")
+	t.Setenv("PROBLEM_DETAILS", "true")
+
+	c, err := Load("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://legacy/blogger", c.Database.URL, "POSTGRESQL_URL is read when DATABASE_URL is unset")
+	assert.Equal(t, []string{"postgres://a/blogger", "postgres://b/blogger"}, c.Database.ReplicaURLs)
+	assert.True(t, c.Server.ProblemDetails)
+
+	t.Setenv("DATABASE_URL", "postgres://db/blogger")
+	c, err = Load("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://db/blogger", c.Database.URL)
+
+	t.Setenv("DB_CONNECT_TIMEOUT", "soon")
+	_, err = Load("", nil)
+	assert.ErrorContains(t, err, "DB_CONNECT_TIMEOUT")
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	t.Setenv("DATABASE_URL_FILE", writeFile(t, "url", "postgres://postgres:secret@db/blogger\n"))
+
+	c, err := Load("", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://postgres:secret@db/blogger", c.Database.URL, "The trailing newline of the secret is trimmed")
+
+	t.Setenv("DATABASE_URL", "postgres://db/blogger")
+	_, err = Load("", nil)
+	assert.ErrorContains(t, err, "cannot both be set")
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(writeFile(t, "config.yaml", "server:\n  prot: 9000\n"), nil)
+	assert.Error(t, err, "Unknown keys are rejected")
+
+	t.Setenv("BLOG_CACHE_SIZE", "-1")
+	t.Setenv("DUPLICATE_ACTION", "shout")
+	t.Setenv("TRACING_EXPORTER", "file")
+	t.Setenv("HTTP_CACHE_SHOW", "no-cache\r\nSet-Cookie: session=stolen")
+	_, err = Load("", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http_cache.show", "Headers cannot be injected")
+	assert.Contains(t, err.Error(), "cache.size", "Every invalid setting is reported")
+	assert.Contains(t, err.Error(), "duplicates.action")
+	assert.Contains(t, err.Error(), "tracing.file")
+	assert.NotContains(t, err.Error(), "database.url", "Commands that do not open the database need none")
+}
+
+func TestFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	require.NoError(t, fs.Parse([]string{"-server.port", "9090", "-server.problem_details", "-database.url", "postgres://db/blogger", "-database.replica_urls", "postgres://a/blogger"}))
+
+	c, err := flags.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 9090, c.Server.Port)
+	assert.True(t, c.Server.ProblemDetails)
+	assert.Equal(t, []string{"postgres://a/blogger"}, c.Database.ReplicaURLs)
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field is a setting of Config, found through the tags of its struct field.
+type field struct {
+	path  string
+	env   []string
+	usage string
+	value reflect.Value
+}
+
+// fields lists the settings of c, which are set through value.
+func fields(c *Config) []field {
+	return walk(reflect.ValueOf(c).Elem(), "")
+}
+
+func walk(v reflect.Value, prefix string) []field {
+	var res []field
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		path := prefix + strings.Split(sf.Tag.Get("yaml"), ",")[0]
+
+		if sf.Type.Kind() == reflect.Struct {
+			res = append(res, walk(v.Field(i), path+".")...)
+			continue
+		}
+
+		var env []string
+		if tag := sf.Tag.Get("env"); tag != "" {
+			env = strings.Split(tag, ",")
+		}
+		res = append(res, field{path: path, env: env, usage: sf.Tag.Get("usage"), value: v.Field(i)})
+	}
+	return res
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set parses s into the field, according to its type. Lists are separated by
+// commas.
+func (f field) set(s string) error {
+	v := f.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 30s, got %q", s)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("expected true or false, got %q", s)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int, v.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", s)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("expected a number, got %q", s)
+		}
+		v.SetFloat(n)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		list := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		v.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Flags are the flags of a command that override the configuration.
+type Flags struct {
+	file   *string
+	values map[string]*flagValue
+}
+
+// RegisterFlags registers `-config`, and a flag for every setting, on fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{
+		file:   fs.String("config", "", "YAML file to read the configuration from (default $CONFIG_FILE)"),
+		values: make(map[string]*flagValue),
+	}
+	for _, field := range fields(Default()) {
+		v := &flagValue{isBool: field.value.Kind() == reflect.Bool}
+		f.values[field.path] = v
+		fs.Var(v, field.path, field.usage)
+	}
+	return f
+}
+
+// Load loads the configuration, once the flags are parsed.
+func (f *Flags) Load() (*Config, error) {
+	file := *f.file
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+
+	flags := make(map[string]string)
+	for path, v := range f.values {
+		if v.set {
+			flags[path] = v.value
+		}
+	}
+	return Load(file, flags)
+}
+
+// flagValue holds the value of a flag until the configuration is loaded, as
+// flags take precedence over the sources read after they are parsed.
+type flagValue struct {
+	value  string
+	set    bool
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	v.value, v.set = s, true
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
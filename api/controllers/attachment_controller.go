@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
+	"example.com/m/v2/ioc"
+	"example.com/m/v2/repositories"
+	services "example.com/m/v2/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// attachmentFormField is the multipart form field holding the uploaded file.
+const attachmentFormField = "file"
+
+type attachmentController struct {
+	attachmentService    services.AttachmentService
+	ioc                  *ioc.IOC
+	attachmentRepository repositories.AttachmentRepository
+	blogRepository       repositories.SingleBlogGetter
+	maxSize              int64
+}
+
+// AttachmentController only implements Create and Show; attachments cannot be
+// listed, changed or removed through the API.
+type AttachmentController interface {
+	Controller
+}
+
+func NewAttachmentController(c *ioc.IOC, s services.AttachmentService, r repositories.AttachmentRepository, b repositories.SingleBlogGetter, maxSize int64) *attachmentController {
+	return &attachmentController{
+		ioc:                  c,
+		attachmentService:    s,
+		attachmentRepository: r,
+		blogRepository:       b,
+		maxSize:              maxSize,
+	}
+}
+
+func (a *attachmentController) Index(c *gin.Context) {
+	HandleAPIError(c, apiErrors.IsNotImplementedError)
+}
+
+func (a *attachmentController) New(c *gin.Context) {
+	HandleAPIError(c, apiErrors.IsNotImplementedError)
+}
+
+// Create uploads an attachment to the blog in the `blog_id` parameter of the
+// nested route.
+func (a *attachmentController) Create(c *gin.Context) {
+	blogID := c.Params.ByName("blog_id")
+	if _, err := strconv.Atoi(blogID); err != nil {
+		HandleAPIError(c, fmt.Errorf("%w: invalid blog id %q", apiErrors.IsBadRequestError, blogID))
+		return
+	}
+
+	// Leave some headroom for the multipart boundaries and headers; the
+	// service enforces the exact limit on the file itself.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, a.maxSize+(1<<20))
+
+	file, header, err := c.Request.FormFile(attachmentFormField)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			err = fmt.Errorf("%w: %s", apiErrors.IsPayloadTooLargeError, err)
+		} else {
+			err = fmt.Errorf("%w: %s", apiErrors.IsBadRequestError, err)
+		}
+		HandleAPIError(c, err)
+		return
+	}
+	defer file.Close()
+
+	res, err := a.attachmentService.Create(c.Request.Context(), &dtos.CreateAttachmentRequest{
+		BlogID:   blogID,
+		Filename: header.Filename,
+		Content:  file,
+	}, a.attachmentRepository, a.blogRepository)
+	if err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+
+	Render(c, http.StatusOK, res)
+}
+
+// Show serves the content of the attachment. The content behind an attachment
+// never changes, so it may be cached indefinitely.
+func (a *attachmentController) Show(c *gin.Context) {
+	id := c.Params.ByName("id")
+	if _, err := strconv.Atoi(id); err != nil {
+		HandleAPIError(c, fmt.Errorf("%w: invalid attachment id %q", apiErrors.IsBadRequestError, id))
+		return
+	}
+
+	res, err := a.attachmentService.GetByID(c.Request.Context(), id, a.attachmentRepository)
+	if err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+
+	etag := `"` + res.Checksum + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	if IsFresh(c.Request, etag, res.CreatedAt) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	content, err := a.attachmentService.Open(res)
+	if err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, res.Size, res.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": res.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+func (a *attachmentController) Edit(c *gin.Context) {
+	HandleAPIError(c, apiErrors.IsNotImplementedError)
+}
+
+func (a *attachmentController) Update(c *gin.Context) {
+	HandleAPIError(c, apiErrors.IsNotImplementedError)
+}
+
+func (a *attachmentController) Delete(c *gin.Context) {
+	HandleAPIError(c, apiErrors.IsNotImplementedError)
+}
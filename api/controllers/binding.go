@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	apiErrors "example.com/m/v2/errors"
+)
+
+// BindingError is returned by Bind when the request body cannot be decoded,
+// or fails the `binding` struct tags checked by gin.
+type BindingError struct {
+	err error
+}
+
+func (e *BindingError) Error() string {
+	return "cannot bind request body: " + e.err.Error()
+}
+
+func (e *BindingError) Unwrap() error {
+	return e.err
+}
+
+func init() {
+	apiErrors.Register("gin.binding", apiErrors.PriorityHigh, mapBindingError)
+}
+
+// mapBindingError reports `binding` tag failures in the same shape as
+// dtos.Validator failures. Anything else that prevents decoding the body is a
+// bad request.
+func mapBindingError(err error) (apiErrors.APIError, bool) {
+	var bindingErr *BindingError
+	if !errors.As(err, &bindingErr) {
+		return apiErrors.APIError{}, false
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return apiErrors.NewStatusError(http.StatusRequestEntityTooLarge, err), true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apiErrors.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			field := strings.ToLower(fe.Field())
+			fields = append(fields, apiErrors.FieldError{
+				Field:   field,
+				Rule:    fe.Tag(),
+				Message: fmt.Sprintf("%s failed the %q rule", field, fe.Tag()),
+			})
+		}
+		return apiErrors.NewAPIError(&apiErrors.ValidationError{Fields: fields}), true
+	}
+
+	return apiErrors.NewStatusError(http.StatusBadRequest, err), true
+}
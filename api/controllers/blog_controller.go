@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	dtos "example.com/m/v2/dtos"
 	apiErrors "example.com/m/v2/errors"
 	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
 	"example.com/m/v2/repositories"
 	services "example.com/m/v2/services"
 
@@ -22,8 +25,15 @@ type blogController struct {
 type BlogController interface {
 	Controller
 	ShowWordCount(*gin.Context)
+	ShowRelated(*gin.Context)
 }
 
+// Bounds of the `limit` query parameter of ShowRelated.
+const (
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = 50
+)
+
 func NewBlogController(c *ioc.IOC, s services.BlogService, r repositories.BlogRepository) *blogController {
 	return &blogController{
 		ioc:            c,
@@ -33,44 +43,70 @@ func NewBlogController(c *ioc.IOC, s services.BlogService, r repositories.BlogRe
 }
 
 func (b *blogController) Index(c *gin.Context) {
-	res, err := b.blogService.GetAll(b.blogRepository)
+	res, err := b.blogService.GetAll(c.Request.Context(), b.reader(c))
 
 	if err != nil {
 		HandleAPIError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	RenderConditional(c, res, lastModified(res))
 }
 
 func (b *blogController) Show(c *gin.Context) {
 	id := c.Params.ByName("id")
-	if _, err := strconv.Atoi(id); err != nil {
-		// fmt.Printf("%q does not look like a number.\n", v)
-		c.Next()
+	if _, err := parseID(id); err != nil {
+		HandleAPIError(c, err)
 		return
 	}
 
-	res, err := b.blogService.GetByID(id, b.blogRepository)
+	res, err := b.blogService.GetByID(c.Request.Context(), id, b.reader(c))
 	if err != nil {
 		HandleAPIError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	RenderConditional(c, res, res.UpdatedAt)
 }
 
 func (b *blogController) ShowWordCount(c *gin.Context) {
 	id := c.Params.ByName("id")
+	if _, err := parseID(id); err != nil {
+		HandleAPIError(c, err)
+		return
+	}
 
-	res, err := b.blogService.GetByID(id, b.blogRepository)
+	res, err := b.blogService.GetByID(c.Request.Context(), id, b.reader(c))
 	if err != nil {
 		HandleAPIError(c, err)
 		return
 	}
 
 	wc := res.GetWordCount()
-	c.JSON(http.StatusOK, wc)
+	RenderConditional(c, wc, res.UpdatedAt)
+}
+
+func (b *blogController) ShowRelated(c *gin.Context) {
+	id := c.Params.ByName("id")
+	if _, err := parseID(id); err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultRelatedLimit)))
+	if err != nil || limit < 1 || limit > maxRelatedLimit {
+		err = fmt.Errorf("%w: limit must be between 1 and %d", apiErrors.IsBadRequestError, maxRelatedLimit)
+		HandleAPIError(c, err)
+		return
+	}
+
+	res, err := b.blogService.GetRelated(c.Request.Context(), id, limit, b.reader(c))
+	if err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+
+	Render(c, http.StatusOK, res)
 }
 
 func (b *blogController) New(c *gin.Context) {
@@ -82,15 +118,19 @@ func (b *blogController) New(c *gin.Context) {
 
 func (b *blogController) Create(c *gin.Context) {
 	reqBody := &dtos.CreateBlogRequest{}
-	c.BindJSON(reqBody)
-	res, err := b.blogService.Create(reqBody, b.blogRepository)
+	if err := Bind(c, reqBody); err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+
+	res, err := b.blogService.Create(c.Request.Context(), reqBody, b.writer(c))
 
 	if err != nil {
 		HandleAPIError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	Render(c, http.StatusOK, res)
 }
 
 func (b *blogController) Edit(c *gin.Context) {
@@ -101,29 +141,36 @@ func (b *blogController) Edit(c *gin.Context) {
 }
 
 func (b *blogController) Update(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Params.ByName("id"), 10, 64)
-
+	id, err := parseID(c.Params.ByName("id"))
 	if err != nil {
 		HandleAPIError(c, err)
 		return
 	}
 
 	reqBody := &dtos.UpdateBlogRequest{}
-	c.BindJSON(reqBody)
-	res, err := b.blogService.Update(uint(id), reqBody, b.blogRepository)
+	if err := Bind(c, reqBody); err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+
+	res, err := b.blogService.Update(c.Request.Context(), id, reqBody, b.writer(c))
 
 	if err != nil {
 		HandleAPIError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	Render(c, http.StatusOK, res)
 }
 
 func (b *blogController) Delete(c *gin.Context) {
-	// TODO: parse uint?
 	id := c.Params.ByName("id")
-	err := b.blogService.Delete(id, b.blogRepository)
+	if _, err := parseID(id); err != nil {
+		HandleAPIError(c, err)
+		return
+	}
+
+	err := b.blogService.Delete(c.Request.Context(), id, b.writer(c))
 
 	if err != nil {
 		HandleAPIError(c, err)
@@ -132,3 +179,58 @@ func (b *blogController) Delete(c *gin.Context) {
 
 	c.Writer.WriteHeader(204)
 }
+
+// parseID parses the ID of a resource from the path, and fails with a 400
+// unless it is a number. The repositories only take numeric IDs.
+func parseID(id string) (uint, error) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid id %q", apiErrors.IsBadRequestError, id)
+	}
+	return uint(n), nil
+}
+
+// lastModified returns the most recent update time across all blogs. Note that
+// a deleted blog does not move this time forward; the ETag still changes in
+// that case, and takes precedence for clients that send `If-None-Match`.
+func lastModified(blogs []*models.Blog) time.Time {
+	var t time.Time
+	for _, blog := range blogs {
+		if blog.UpdatedAt.After(t) {
+			t = blog.UpdatedAt
+		}
+	}
+	return t
+}
+
+// reader returns the repository to read from. Requests with the
+// `X-Cache-Bypass` header skip the cache, if any, for debugging. The header is
+// ignored unless `server.expose_routes` is set, as any client could otherwise
+// send every read to the database.
+func (b *blogController) reader(c *gin.Context) repositories.BlogRepository {
+	r := b.blogRepository
+	if bypass, _ := strconv.ParseBool(c.GetHeader(CacheBypassHeader)); bypass && b.ioc.Config.Server.ExposeRoutes {
+		if cached, ok := r.(repositories.CacheBypasser); ok {
+			r = cached.Bypass()
+		}
+	}
+	return b.session(c, r)
+}
+
+// writer returns the repository to write to. Writes always go through the
+// cache, which must see them to invalidate itself.
+func (b *blogController) writer(c *gin.Context) repositories.BlogRepository {
+	return b.session(c, b.blogRepository)
+}
+
+// session scopes r to the request, if it supports sessions, so that the
+// request reads its own writes. Requests with the `X-Read-Your-Writes` header
+// read from the primary database from the start, e.g. right after a client
+// wrote in an earlier request.
+func (b *blogController) session(c *gin.Context, r repositories.BlogRepository) repositories.BlogRepository {
+	if sessioner, ok := r.(repositories.BlogSessioner); ok {
+		readPrimary, _ := strconv.ParseBool(c.GetHeader(ReadYourWritesHeader))
+		return sessioner.Session(readPrimary)
+	}
+	return r
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	repositories "example.com/m/v2/repositories"
+	mocks "example.com/m/v2/repositories/mocks"
+)
+
+// blogServiceStub fails every call with err, wrapped like the real service.
+type blogServiceStub struct {
+	err error
+}
+
+func (s blogServiceStub) fail() error {
+	return apiErrors.WithResource("blog", s.err)
+}
+
+func (s blogServiceStub) Create(_ context.Context, m *dtos.CreateBlogRequest, r repositories.BlogCreator) (*models.Blog, error) {
+	return nil, s.fail()
+}
+
+func (s blogServiceStub) GetByID(_ context.Context, id string, r repositories.SingleBlogGetter) (*models.Blog, error) {
+	return nil, s.fail()
+}
+
+func (s blogServiceStub) GetAll(_ context.Context, r repositories.MultiBlogGetter) ([]*models.Blog, error) {
+	return nil, s.fail()
+}
+
+func (s blogServiceStub) Update(_ context.Context, id uint, m *dtos.UpdateBlogRequest, r repositories.BlogUpdater) (*models.Blog, error) {
+	return nil, s.fail()
+}
+
+func (s blogServiceStub) Delete(_ context.Context, id string, r repositories.BlogDeleter) error {
+	return s.fail()
+}
+
+func (s blogServiceStub) GetRelated(_ context.Context, id string, limit int, r repositories.RelatedBlogGetter) ([]*dtos.RelatedBlogResponse, error) {
+	return nil, s.fail()
+}
+
+func (s blogServiceStub) IndexAll(_ context.Context, r repositories.BlogIndexer) error {
+	return s.fail()
+}
+
+// TestBlogControllerErrorCodes enforces that every error code returned by the
+// blog handlers is documented in the error catalog.
+func TestBlogControllerErrorCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serviceErrors := []error{
+		errors.New("unknown"),
+		gorm.ErrRecordNotFound,
+		&pgconn.PgError{Code: apiErrors.DUPLICATED_KEY, TableName: "blogs", ConstraintName: "blogs_title_key"},
+		&pgconn.PgError{Code: apiErrors.STRING_DATA_RIGHT_TRUNCATION},
+		&pgconn.PgError{Code: apiErrors.SERIALIZATION_FAILURE},
+		&apiErrors.NearDuplicateError{ConflictingID: 1},
+	}
+
+	requests := []struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+	}{
+		{http.MethodGet, "/blogs/", "", ""},
+		{http.MethodGet, "/blogs/new", "", ""},
+		{http.MethodGet, "/blogs/1", "", ""},
+		{http.MethodGet, "/blogs/1/words", "", ""},
+		{http.MethodGet, "/blogs/1/related", "", ""},
+		{http.MethodGet, "/blogs/1/related?limit=0", "", ""},
+		{http.MethodGet, "/blogs/x/related", "", ""},
+		{http.MethodPost, "/blogs/", "application/json", `{"title":"t","body":"b"}`},
+		{http.MethodPost, "/blogs/", "application/json", `{"title":"","body":""}`},
+		{http.MethodPost, "/blogs/", "application/json", `{`},
+		{http.MethodPost, "/blogs/", "text/csv", "t,b"},
+		{http.MethodPut, "/blogs/1", "application/json", `{"title":"t","body":"b"}`},
+		{http.MethodPut, "/blogs/x", "application/json", `{"title":"t","body":"b"}`},
+		{http.MethodDelete, "/blogs/1", "", ""},
+		{http.MethodDelete, "/blogs/x", "", ""},
+	}
+
+	for _, serviceErr := range serviceErrors {
+		c := ioc.NewContainer()
+		r := gin.New()
+		controller := NewBlogController(&c, blogServiceStub{err: serviceErr}, &mocks.BlogRepositoryMock{})
+
+		// The router package depends on this one, so the routes are
+		// registered here instead.
+		routes := r.Group("/blogs")
+		routes.GET("/", controller.Index)
+		routes.GET("/new", controller.New)
+		routes.GET("/:id", controller.Show)
+		routes.GET("/:id/words", controller.ShowWordCount)
+		routes.GET("/:id/related", controller.ShowRelated)
+		routes.POST("/", controller.Create)
+		routes.PUT("/:id", controller.Update)
+		routes.DELETE("/:id", controller.Delete)
+
+		for _, req := range requests {
+			httpReq := httptest.NewRequest(req.method, req.path, strings.NewReader(req.body))
+			if req.contentType != "" {
+				httpReq.Header.Set("Content-Type", req.contentType)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httpReq)
+
+			var body map[string]any
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body), "%s %s", req.method, req.path)
+
+			code, _ := body["error_code"].(string)
+			assert.True(t, apiErrors.InCatalog(code), "%s %s returned %q, which is not in the catalog", req.method, req.path, code)
+		}
+	}
+}
+
+// TestInvalidID checks that every action on a blog rejects a malformed id,
+// before the service is called.
+func TestInvalidID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := ioc.NewContainer()
+	r := gin.New()
+	controller := NewBlogController(&c, blogServiceStub{err: errors.New("unreachable")}, &mocks.BlogRepositoryMock{})
+	r.GET("/blogs/:id", controller.Show)
+	r.GET("/blogs/:id/words", controller.ShowWordCount)
+	r.GET("/blogs/:id/related", controller.ShowRelated)
+	r.PUT("/blogs/:id", controller.Update)
+	r.DELETE("/blogs/:id", controller.Delete)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/blogs/abc", nil),
+		httptest.NewRequest(http.MethodGet, "/blogs/-1", nil),
+		httptest.NewRequest(http.MethodGet, "/blogs/abc/words", nil),
+		httptest.NewRequest(http.MethodGet, "/blogs/"+url.PathEscape("1 OR 1=1")+"/related", nil),
+		httptest.NewRequest(http.MethodPut, "/blogs/x", strings.NewReader(`{"title":"t","body":"b"}`)),
+		httptest.NewRequest(http.MethodDelete, "/blogs/abc", nil),
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "%s %s", req.Method, req.URL)
+	}
+}
+
+// bypassableRepository is a cache in front of inner.
+type bypassableRepository struct {
+	*mocks.BlogRepositoryMock
+	inner repositories.BlogRepository
+}
+
+func (r bypassableRepository) Bypass() repositories.BlogRepository {
+	return r.inner
+}
+
+// readerSpy records the repository that the blogs are read from.
+type readerSpy struct {
+	blogServiceStub
+	read repositories.MultiBlogGetter
+}
+
+func (s *readerSpy) GetAll(_ context.Context, r repositories.MultiBlogGetter) ([]*models.Blog, error) {
+	s.read = r
+	return []*models.Blog{}, nil
+}
+
+func TestCacheBypassHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	inner := &mocks.BlogRepositoryMock{}
+	cached := bypassableRepository{BlogRepositoryMock: &mocks.BlogRepositoryMock{}, inner: inner}
+
+	for _, expose := range []bool{false, true} {
+		c := ioc.NewContainer()
+		c.Config.Server.ExposeRoutes = expose
+		spy := &readerSpy{}
+		r := gin.New()
+		r.GET("/blogs/", NewBlogController(&c, spy, cached).Index)
+
+		req := httptest.NewRequest(http.MethodGet, "/blogs/", nil)
+		req.Header.Set(CacheBypassHeader, "true")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if expose {
+			assert.Same(t, inner, spy.read, "The cache is bypassed with server.expose_routes")
+		} else {
+			assert.Equal(t, cached, spy.read, "The header is ignored without server.expose_routes")
+		}
+	}
+}
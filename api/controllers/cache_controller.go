@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"net/http"
+
+	"example.com/m/v2/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShowCacheStats reports the hits and misses of each cache of the repository.
+func ShowCacheStats(r repositories.CacheStatser) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		Render(c, http.StatusOK, r.Stats())
+	}
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RenderConditional renders obj like Render, but first attaches the `ETag` and
+// `Last-Modified` validators to the response. If the client's cached copy is
+// still fresh according to `If-None-Match` or `If-Modified-Since`, a
+// `304 Not Modified` is sent instead of the body.
+//
+// A zero lastModified omits the `Last-Modified` header, leaving the `ETag` as
+// the only validator.
+func RenderConditional(c *gin.Context, obj any, lastModified time.Time) {
+	etag, err := ETag(Negotiate(c), obj)
+	if err != nil {
+		// The validators are an optimisation; still serve the full body.
+		Render(c, http.StatusOK, obj)
+		return
+	}
+
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if IsFresh(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	Render(c, http.StatusOK, obj)
+}
+
+// ETag returns a strong entity tag for obj. The negotiated media type is part
+// of the tag since each representation of the same resource differs.
+func ETag(mediaType string, obj any) (string, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(mediaType))
+	h.Write(b)
+	return `"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`, nil
+}
+
+// IsFresh reports whether the client already holds the current representation.
+// Per RFC 7232, `If-Modified-Since` is ignored when `If-None-Match` is present.
+func IsFresh(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		// HTTP dates only have a precision of one second.
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// etagMatches uses the weak comparison function, as required for
+// `If-None-Match`.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
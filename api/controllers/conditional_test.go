@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderConditional(t *testing.T) {
+	modified := time.Date(2023, 5, 24, 8, 19, 50, 999, time.UTC)
+	obj := map[string]int{"red": 3}
+	etag, _ := ETag("application/json", obj)
+
+	tests := [...]struct {
+		name    string
+		headers map[string]string
+		code    int
+	}{
+		{"NoValidators", nil, http.StatusOK},
+		{"MatchingETag", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"WeakMatchingETag", map[string]string{"If-None-Match": `"other", W/` + etag}, http.StatusNotModified},
+		{"StaleETag", map[string]string{"If-None-Match": `"other"`}, http.StatusOK},
+		{"NotModifiedSince", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"ModifiedSince", map[string]string{"If-Modified-Since": modified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{
+			"ETagTakesPrecedence",
+			map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": modified.Format(http.TimeFormat)},
+			http.StatusOK,
+		},
+		{"DifferentRepresentation", map[string]string{"If-None-Match": etag, "Accept": "application/xml"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext(http.MethodGet, "", tt.headers)
+			RenderConditional(c, obj, modified)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, "Wed, 24 May 2023 08:19:50 GMT", w.Header().Get("Last-Modified"))
+			assert.NotEmpty(t, w.Header().Get("ETag"))
+			if tt.code == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		})
+	}
+}
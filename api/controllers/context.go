@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Keys of the values that middleware stores on the gin.Context for the
+// controllers to use.
+const (
+	RequestIDKey      = "request_id"
+	ProblemDetailsKey = "problem_details"
+)
+
+// Request headers that change how the repositories are read from, when set to
+// "true". CacheBypassHeader skips the cache, and ReadYourWritesHeader reads
+// from the primary database instead of a replica.
+const (
+	CacheBypassHeader    = "X-Cache-Bypass"
+	ReadYourWritesHeader = "X-Read-Your-Writes"
+)
+
+// GetRequestID returns the ID of the current request, or an empty string if
+// the request ID middleware is not in use.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
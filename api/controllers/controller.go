@@ -1,7 +1,11 @@
 package controllers
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
 
 	apiErrors "example.com/m/v2/errors"
 )
@@ -21,21 +25,90 @@ type Controller interface {
 // HandleAPIError will take a plan error object and parse it into into
 // an APIError. If the type of error is not handled, it will become
 // a simple error.
+//
+// The error is rendered as RFC 7807 problem details if the client asks for
+// them in its `Accept` header, or if they are enabled for the route. Otherwise
+// the original `{code, message}` shape is kept for existing clients.
 func HandleAPIError(c *gin.Context, err error) {
 	// Process the error for known errors mapped to specific HTTP responses and
 	// messages
 	apiError := apiErrors.NewAPIError(err)
 
+	// Only the client-side messages are localized; the server-side log below
+	// stays in English.
+	locale := apiErrors.MatchLocale(c.GetHeader("Accept-Language"))
+	apiError = apiError.Localize(locale)
+	c.Header("Content-Language", locale.String())
+
 	// Set the response body for the client.
 	// NOTE: Gin will not return a body for `no content` status codes,
 	// such as 204.
-	c.JSON(apiError.Code, gin.H{
-		"code":    apiError.Code,
-		"message": apiError.GetMessage(),
-	})
+	if wantsProblem(c) {
+		renderProblem(c, apiError)
+	} else {
+		renderError(c, apiError)
+	}
 
 	// Invoke error handler with error code for client and a message for
 	// the server. Do not override the error code.
 	c.AbortWithError(-1, apiError.Unwrap())
 	return
 }
+
+func renderError(c *gin.Context, apiError apiErrors.APIError) {
+	body := gin.H{}
+	for k, v := range apiError.GetDetails() {
+		body[k] = v
+	}
+	body["code"] = apiError.Code
+	body["error_code"] = apiError.GetErrorCode()
+	body["message"] = apiError.GetMessage()
+
+	// If the client does not accept any of our formats, fall back to the
+	// default one rather than not describing the error at all.
+	if Negotiate(c) == "" {
+		c.JSON(apiError.Code, body)
+	} else {
+		Render(c, apiError.Code, body)
+	}
+}
+
+// problemOffered is like Offered, but prefers the problem details media types.
+var problemOffered = []string{
+	apiErrors.MIMEProblemJSON,
+	apiErrors.MIMEProblemXML,
+	binding.MIMEJSON,
+	binding.MIMEXML,
+	binding.MIMEXML2,
+	binding.MIMEYAML,
+	binding.MIMEMSGPACK,
+	binding.MIMEMSGPACK2,
+}
+
+func renderProblem(c *gin.Context, apiError apiErrors.APIError) {
+	problem := apiError.Problem(c.Request.URL.RequestURI())
+	if id := GetRequestID(c); id != "" {
+		problem.Extensions["request_id"] = id
+	}
+
+	// Gin does not override a `Content-Type` that has already been set.
+	switch c.NegotiateFormat(problemOffered...) {
+	case apiErrors.MIMEProblemXML, binding.MIMEXML, binding.MIMEXML2:
+		c.Header("Content-Type", apiErrors.MIMEProblemXML+"; charset=utf-8")
+		c.XML(apiError.Code, problem)
+	case binding.MIMEYAML:
+		c.YAML(apiError.Code, problem.Map())
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		c.Render(apiError.Code, render.MsgPack{Data: problem.Map()})
+	default:
+		c.Header("Content-Type", apiErrors.MIMEProblemJSON+"; charset=utf-8")
+		c.JSON(apiError.Code, problem)
+	}
+}
+
+func wantsProblem(c *gin.Context) bool {
+	if c.GetBool(ProblemDetailsKey) {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), "application/problem+")
+}
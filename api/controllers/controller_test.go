@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiErrors "example.com/m/v2/errors"
+)
+
+func TestHandleAPIError(t *testing.T) {
+	err := &apiErrors.ValidationError{Fields: []apiErrors.FieldError{
+		{Field: "title", Rule: "required", Message: "title is required"},
+	}}
+
+	t.Run("Legacy", func(t *testing.T) {
+		c, w := newTestContext(http.MethodPost, "", map[string]string{"Accept": "application/json"})
+		HandleAPIError(c, err)
+
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, float64(http.StatusUnprocessableEntity), body["code"])
+		assert.Equal(t, "Unprocessable Entity", body["message"])
+		assert.Len(t, body["errors"], 1)
+		assert.NotContains(t, body, "type")
+	})
+
+	t.Run("ProblemFromAccept", func(t *testing.T) {
+		c, w := newTestContext(http.MethodPost, "", map[string]string{"Accept": "application/problem+json"})
+		c.Set(RequestIDKey, "abc123")
+		HandleAPIError(c, err)
+
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "application/problem+json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "about:blank", body["type"])
+		assert.Equal(t, "Unprocessable Entity", body["title"])
+		assert.Equal(t, float64(http.StatusUnprocessableEntity), body["status"])
+		assert.Equal(t, "One or more fields are invalid.", body["detail"])
+		assert.Equal(t, "/", body["instance"])
+		assert.Equal(t, "abc123", body["request_id"])
+		assert.Len(t, body["errors"], 1)
+	})
+
+	t.Run("ProblemFromConfig", func(t *testing.T) {
+		c, w := newTestContext(http.MethodPost, "", map[string]string{"Accept": "application/xml"})
+		c.Set(ProblemDetailsKey, true)
+		HandleAPIError(c, apiErrors.IsNotImplementedError)
+
+		assert.Equal(t, "application/problem+xml; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.True(t, strings.HasPrefix(w.Body.String(), `<problem xmlns="urn:ietf:rfc:7807">`))
+		assert.Contains(t, w.Body.String(), "<status>501</status>")
+	})
+}
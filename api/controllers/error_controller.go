@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"net/http"
+
+	apiErrors "example.com/m/v2/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShowErrorCatalog lists every error code that the API may return, so that
+// clients can discover them without reading the docs.
+func ShowErrorCatalog(c *gin.Context) {
+	Render(c, http.StatusOK, apiErrors.Catalog)
+}
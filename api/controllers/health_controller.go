@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"net/http"
+
+	health "example.com/m/v2/pkg/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShowHealth runs the checks of the probe, and reports each of them. It
+// responds 503 Service Unavailable if any fails, as that is all that probes
+// look at.
+func ShowHealth(h *health.Registry, probe health.Probe) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := h.Run(c.Request.Context(), probe)
+		status := http.StatusOK
+		if !report.Passed() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+
+	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
+)
+
+// Offered is the list of media types that controllers are able to render, in
+// order of preference. The first entry is used when the client does not send
+// an `Accept` header (or sends `*/*`).
+//
+// The problem details types are offered last: a client that only accepts
+// them (to read errors) gets successful responses as plain JSON or XML.
+var Offered = []string{
+	binding.MIMEJSON,
+	binding.MIMEXML,
+	binding.MIMEXML2,
+	binding.MIMEYAML,
+	binding.MIMEMSGPACK,
+	binding.MIMEMSGPACK2,
+	apiErrors.MIMEProblemJSON,
+	apiErrors.MIMEProblemXML,
+}
+
+// Negotiate returns the media type that satisfies the client's `Accept`
+// header, or an empty string if none of the Offered types are acceptable.
+func Negotiate(c *gin.Context) string {
+	return c.NegotiateFormat(Offered...)
+}
+
+// Render writes obj to the response in the format negotiated from the
+// `Accept` header. Controllers should call this instead of `c.JSON` so that
+// every action supports the same set of formats.
+func Render(c *gin.Context, code int, obj any) {
+	switch Negotiate(c) {
+	case binding.MIMEJSON, apiErrors.MIMEProblemJSON:
+		c.JSON(code, obj)
+	case binding.MIMEXML, binding.MIMEXML2, apiErrors.MIMEProblemXML:
+		c.XML(code, toXML(obj))
+	case binding.MIMEYAML:
+		c.YAML(code, obj)
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		c.Render(code, render.MsgPack{Data: obj})
+	default:
+		HandleAPIError(c, apiErrors.IsNotAcceptableError)
+	}
+}
+
+// Bind decodes the request body into obj according to its `Content-Type`.
+// A missing `Content-Type` is treated as JSON for backwards compatibility.
+// If obj implements dtos.Validator, it is validated once decoded.
+func Bind(c *gin.Context, obj any) error {
+	var b binding.Binding
+
+	switch c.ContentType() {
+	case "", binding.MIMEJSON:
+		b = binding.JSON
+	case binding.MIMEXML, binding.MIMEXML2:
+		b = binding.XML
+	case binding.MIMEYAML:
+		b = binding.YAML
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		b = binding.MsgPack
+	default:
+		return fmt.Errorf("%w: %s", apiErrors.IsUnsupportedMediaTypeError, c.ContentType())
+	}
+
+	if err := c.ShouldBindWith(obj, b); err != nil {
+		return &BindingError{err: err}
+	}
+
+	if v, ok := obj.(dtos.Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// xmlList wraps a slice so that it is rendered with a single root element;
+// `encoding/xml` would otherwise emit one root element per item.
+type xmlList struct {
+	XMLName xml.Name `xml:"items"`
+	Items   any      `xml:"item"`
+}
+
+// xmlEntry is a single key/value pair of a map. Map keys are not guaranteed
+// to be valid XML element names (e.g. word counts), so they are rendered as
+// attributes instead.
+type xmlEntry struct {
+	Key   string `xml:"key,attr"`
+	Value any    `xml:",chardata"`
+}
+
+type xmlMap struct {
+	XMLName xml.Name   `xml:"entries"`
+	Entries []xmlEntry `xml:"entry"`
+}
+
+var xmlMarshalerType = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+
+// toXML adapts values that `encoding/xml` cannot render on its own. Types
+// that know how to marshal themselves (such as gin.H) are left untouched.
+func toXML(obj any) any {
+	if obj == nil || reflect.TypeOf(obj).Implements(xmlMarshalerType) {
+		return obj
+	}
+
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return xmlList{Items: obj}
+	case reflect.Map:
+		m := xmlMap{Entries: make([]xmlEntry, 0, v.Len())}
+		iter := v.MapRange()
+		for iter.Next() {
+			m.Entries = append(m.Entries, xmlEntry{
+				Key:   fmt.Sprint(iter.Key().Interface()),
+				Value: iter.Value().Interface(),
+			})
+		}
+		// Map iteration order is random; keep the output stable.
+		sort.Slice(m.Entries, func(i, j int) bool {
+			return m.Entries[i].Key < m.Entries[j].Key
+		})
+		return m
+	}
+
+	return obj
+}
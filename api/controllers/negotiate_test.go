@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	dtos "example.com/m/v2/dtos"
+)
+
+func newTestContext(method string, body string, headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/", strings.NewReader(body))
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	return c, w
+}
+
+func TestRender(t *testing.T) {
+	tests := [...]struct {
+		name        string
+		accept      string
+		code        int
+		contentType string
+		body        string
+	}{
+		{"DefaultsToJSON", "", http.StatusOK, "application/json", `{"red":3}`},
+		{"Wildcard", "*/*", http.StatusOK, "application/json", `{"red":3}`},
+		{"XML", "application/xml", http.StatusOK, "application/xml", `<entries><entry key="red">3</entry></entries>`},
+		{"YAML", "application/x-yaml", http.StatusOK, "application/x-yaml", "red: 3\n"},
+		{"MsgPack", "application/msgpack", http.StatusOK, "application/msgpack", ""},
+		{"NotAcceptable", "text/csv", http.StatusNotAcceptable, "application/json", `"code":406`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext(http.MethodGet, "", map[string]string{"Accept": tt.accept})
+			Render(c, http.StatusOK, map[string]int{"red": 3})
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), tt.contentType))
+			assert.Contains(t, w.Body.String(), tt.body)
+		})
+	}
+}
+
+func TestRenderXMLList(t *testing.T) {
+	c, w := newTestContext(http.MethodGet, "", map[string]string{"Accept": "text/xml"})
+	Render(c, http.StatusOK, []string{"a", "b"})
+
+	assert.Equal(t, "<items><item>a</item><item>b</item></items>", w.Body.String())
+}
+
+func TestBind(t *testing.T) {
+	tests := [...]struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+	}{
+		{"JSON", "application/json", `{"title":"t","body":"b"}`, 0},
+		{"NoContentType", "", `{"title":"t","body":"b"}`, 0},
+		{"XML", "application/xml", `<blog><title>t</title><body>b</body></blog>`, 0},
+		{"YAML", "application/x-yaml", "title: t\nbody: b\n", 0},
+		{"Malformed", "application/json", `{`, http.StatusBadRequest},
+		{"Invalid", "application/json", `{"title":"  ","body":"b"}`, http.StatusUnprocessableEntity},
+		{"Unsupported", "text/csv", "t,b", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newTestContext(http.MethodPost, tt.body, map[string]string{"Content-Type": tt.contentType})
+			req := &dtos.CreateBlogRequest{}
+			err := Bind(c, req)
+
+			if tt.code == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, "t", req.Title)
+				assert.Equal(t, "b", req.Body)
+				return
+			}
+
+			HandleAPIError(c, err)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/http"
+
+	dtos "example.com/m/v2/dtos"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShowRoutes lists the routes of the engine, including its own.
+func ShowRoutes(r *gin.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		routes := r.Routes()
+		res := make([]dtos.RouteResponse, 0, len(routes))
+		for _, route := range routes {
+			res = append(res, dtos.RouteResponse{
+				Method:  route.Method,
+				Path:    route.Path,
+				Handler: route.Handler,
+			})
+		}
+		Render(c, http.StatusOK, res)
+	}
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"example.com/m/v2/config"
+	"example.com/m/v2/ioc"
+	"gorm.io/gorm"
+)
+
+// DefaultHealthCheckInterval is how often Monitor pings the replicas.
+const DefaultHealthCheckInterval = 10 * time.Second
+
+type replica struct {
+	name    string
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// Cluster routes queries between a primary database, which takes every write,
+// and its read replicas. Replicas that fail a health check are dropped from
+// the rotation until they pass one again. Without healthy replicas, reads go
+// to the primary.
+type Cluster struct {
+	ioc      *ioc.IOC
+	primary  *gorm.DB
+	replicas []*replica
+	next     atomic.Uint64
+
+	// primaryDown is only used to log when the primary is lost and regained;
+	// the pool reconnects by itself.
+	primaryDown atomic.Bool
+}
+
+// ConnectCluster connects to the primary at config.URL, and to the replicas at
+// config.ReplicaURLs. Replicas that cannot be reached yet do not prevent
+// startup; they join the rotation once healthy.
+func ConnectCluster(c *ioc.IOC, config config.Database) (*Cluster, error) {
+	primary, err := Connect(c, config)
+	if err != nil {
+		return nil, err
+	}
+	cluster := NewCluster(c, primary)
+
+	for i, dsn := range config.ReplicaURLs {
+		dialector, err := Dialector(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		db, err := open(dialector, config, true)
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		if err := instrument(c, db, "replica_"+strconv.Itoa(i)); err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		r := &replica{name: "replica " + strconv.Itoa(i) + " (" + Redact(dsn) + ")", db: db}
+		r.healthy.Store(true)
+		cluster.replicas = append(cluster.replicas, r)
+	}
+
+	cluster.CheckHealth(context.Background())
+	return cluster, nil
+}
+
+// NewCluster is used to build a Cluster from open databases, e.g. in tests.
+// Replicas are assumed to be healthy until checked.
+func NewCluster(c *ioc.IOC, primary *gorm.DB, replicas ...*gorm.DB) *Cluster {
+	cluster := &Cluster{ioc: c, primary: primary}
+	for i, db := range replicas {
+		r := &replica{name: "replica " + strconv.Itoa(i), db: db}
+		r.healthy.Store(true)
+		cluster.replicas = append(cluster.replicas, r)
+	}
+	return cluster
+}
+
+// Primary returns the database to write to, and to read your own writes from.
+func (c *Cluster) Primary() *gorm.DB {
+	return c.primary
+}
+
+// Replica returns a healthy replica to read from, in turn.
+func (c *Cluster) Replica() *gorm.DB {
+	for range c.replicas {
+		r := c.replicas[c.next.Add(1)%uint64(len(c.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return c.primary
+}
+
+// CheckHealth pings every database, and updates the rotation of replicas.
+func (c *Cluster) CheckHealth(ctx context.Context) {
+	err := ping(ctx, c.primary)
+	if down := err != nil; c.primaryDown.Swap(down) != down {
+		if down {
+			c.ioc.Logger.Error("Lost the connection to the primary database:", err)
+		} else {
+			c.ioc.Logger.Info("Reconnected to the primary database")
+		}
+	}
+
+	for _, r := range c.replicas {
+		err := ping(ctx, r.db)
+		healthy := err == nil
+		if r.healthy.Swap(healthy) != healthy {
+			if healthy {
+				c.ioc.Logger.Info("Added", r.name, "to the rotation")
+			} else {
+				c.ioc.Logger.Warn("Dropped", r.name, "from the rotation:", err)
+			}
+		}
+	}
+}
+
+// Monitor checks the health of the databases every interval, until ctx is
+// done.
+func (c *Cluster) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckHealth(ctx)
+		}
+	}
+}
+
+// Ping pings the primary. The replicas are left out, as reads fall back to the
+// primary when none is healthy.
+func (c *Cluster) Ping(ctx context.Context) error {
+	return ping(ctx, c.primary)
+}
+
+func ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the pools of every database.
+func (c *Cluster) Close() error {
+	dbs := []*gorm.DB{c.primary}
+	for _, r := range c.replicas {
+		dbs = append(dbs, r.db)
+	}
+
+	var err error
+	for _, db := range dbs {
+		sqlDB, dbErr := db.DB()
+		if dbErr == nil {
+			dbErr = sqlDB.Close()
+		}
+		if err == nil {
+			err = dbErr
+		}
+	}
+	return err
+}
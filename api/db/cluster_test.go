@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"example.com/m/v2/ioc"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	// Every connection to ":memory:" opens a new, empty database.
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+func TestClusterHealth(t *testing.T) {
+	c := ioc.NewContainer()
+	primary, healthy, failing := openSQLite(t), openSQLite(t), openSQLite(t)
+	cluster := NewCluster(&c, primary, healthy, failing)
+
+	sqlDB, _ := failing.DB()
+	assert.NoError(t, sqlDB.Close())
+	cluster.CheckHealth(context.Background())
+
+	for i := 0; i < 4; i++ {
+		assert.Same(t, healthy, cluster.Replica(), "Failing replicas are dropped from the rotation")
+	}
+
+	sqlDB, _ = healthy.DB()
+	assert.NoError(t, sqlDB.Close())
+	cluster.CheckHealth(context.Background())
+	assert.Same(t, primary, cluster.Replica(), "Reads fall back to the primary")
+}
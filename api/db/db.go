@@ -1,23 +1,182 @@
 package database
 
 import (
-	"os"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"example.com/m/v2/config"
+	"example.com/m/v2/ioc"
+	retry "example.com/m/v2/pkg/retry"
+
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 )
 
-func Connect() *gorm.DB {
+// Dialects supported by Dialector.
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
 
-	db, err := gorm.Open(postgres.Open(os.Getenv("POSTGRESQL_URL")), &gorm.Config{
-		Logger: gormLogger.Default.LogMode(gormLogger.Info),
+// Connect opens the primary database at config.URL. While the database is
+// unavailable, e.g. when it is starting alongside the API, it retries with
+// backoff until config.ConnectTimeout has passed.
+//
+// Once connected, connections that break are replaced by the pool on their
+// next use, so losing the database mid-run does not need a restart. Its
+// queries and pool are measured as the "primary" database.
+func Connect(c *ioc.IOC, config config.Database) (*gorm.DB, error) {
+	// Checked here rather than when loading the configuration, since only
+	// the commands that open the database need one.
+	if config.URL == "" {
+		return nil, errors.New("database.url must be set with the postgres backend")
+	}
+	dialector, err := Dialector(config.URL)
+	if err != nil {
+		return nil, err
+	}
+	if IsMemory(config.URL) {
+		// Every connection to an in-memory database opens a new, empty one;
+		// keep a single connection open for the life of the pool.
+		config.MaxOpenConns, config.MaxIdleConns = 1, 1
+		config.ConnMaxLifetime, config.ConnMaxIdleTime = 0, 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.ConnectTimeout)
+	defer cancel()
+
+	var db *gorm.DB
+	err = retry.Do(ctx, retry.DefaultBackoff, func() error {
+		db, err = open(dialector, config, false)
+		return err
+	}, func(err error, delay time.Duration) {
+		c.Logger.Warn("Failed to connect to the database, retrying in", delay.Round(time.Millisecond), "after:", err)
 	})
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the database within %s: %w", config.ConnectTimeout, err)
+	}
+	if err := instrument(c, db, "primary"); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// open opens a database with the pool settings of config. Unless lazy, it
+// fails if the database cannot be reached.
+func open(dialector gorm.Dialector, config config.Database, lazy bool) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               gormLogger.Default.LogMode(gormLogger.Info),
+		DisableAutomaticPing: lazy,
+	})
+	if err != nil {
+		// gorm opens the pool before pinging the database.
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+		return nil, err
+	}
 
+	sqlDB, err := db.DB()
 	if err != nil {
-		// TODO: handle me!
-		panic("Failed to connect database")
+		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	return db, nil
+}
 
-	return db
+// Dialect returns the dialect of a DSN from its scheme: `postgres://` (or
+// `postgresql://`) for PostgreSQL, and `sqlite://` (or `sqlite:`) for SQLite.
+// DSNs without a scheme are PostgreSQL `key=value` connection strings.
+func Dialect(dsn string) (string, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return DialectPostgres, nil
+	case strings.HasPrefix(dsn, "sqlite:"):
+		return DialectSQLite, nil
+	case !strings.Contains(dsn, "://"):
+		return DialectPostgres, nil
+	}
+	return "", fmt.Errorf("unsupported database URL %q: expected a postgres:// or sqlite:// scheme", Redact(dsn))
+}
+
+// Dialector returns the gorm dialector for a DSN. SQLite DSNs name a file, e.g.
+// `sqlite://blogger.db`, or `sqlite::memory:` for a throwaway database (see
+// IsMemory).
+func Dialector(dsn string) (gorm.Dialector, error) {
+	dialect, err := Dialect(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch dialect {
+	case DialectSQLite:
+		path := strings.TrimPrefix(strings.TrimPrefix(dsn, "sqlite:"), "//")
+		// SQLite does not enforce foreign keys unless asked to, unlike
+		// PostgreSQL.
+		if !strings.Contains(path, "foreign_keys") {
+			separator := "?"
+			if strings.Contains(path, "?") {
+				separator = "&"
+			}
+			path += separator + "_pragma=foreign_keys(1)"
+		}
+		return sqlite.Open(path), nil
+	default:
+		return postgres.Open(dsn), nil
+	}
+}
+
+// IsMemory reports whether a DSN names an in-memory SQLite database, which
+// only lives as long as its connection.
+func IsMemory(dsn string) bool {
+	if dialect, err := Dialect(dsn); err != nil || dialect != DialectSQLite {
+		return false
+	}
+	path := strings.TrimPrefix(strings.TrimPrefix(dsn, "sqlite:"), "//")
+	return strings.HasPrefix(path, ":memory:") || strings.Contains(path, "mode=memory")
+}
+
+// Redact hides everything but the scheme of a DSN, which may hold a password.
+func Redact(dsn string) string {
+	if i := strings.Index(dsn, "://"); i >= 0 {
+		return dsn[:i+3] + "..."
+	}
+	return "..."
+}
+
+// aroundQueries registers callbacks that run before and after every query of
+// db, as returned for the kind of query by before and after, e.g. "create".
+// name prefixes the names of the callbacks.
+func aroundQueries(db *gorm.DB, name string, before, after func(operation string) func(*gorm.DB)) error {
+	callbacks := db.Callback()
+	for _, p := range []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	} {
+		if err := p.before(name+":before_"+p.operation, before(p.operation)); err != nil {
+			return err
+		}
+		if err := p.after(name+":after_"+p.operation, after(p.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/m/v2/config"
+	"example.com/m/v2/ioc"
+)
+
+func TestDialect(t *testing.T) {
+	tests := [...]struct {
+		dsn     string
+		dialect string
+	}{
+		{"postgres://postgres:postgres@db:5432/blogger?sslmode=disable", DialectPostgres},
+		{"postgresql://db/blogger", DialectPostgres},
+		{"host=db user=postgres dbname=blogger", DialectPostgres},
+		{"sqlite://blogger.db", DialectSQLite},
+		{"sqlite::memory:", DialectSQLite},
+	}
+	for _, tt := range tests {
+		dialect, err := Dialect(tt.dsn)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.dialect, dialect, tt.dsn)
+	}
+
+	_, err := Dialect("mysql://root:secret@db/blogger")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret", "The password is not leaked")
+}
+
+func TestConnectWithoutURL(t *testing.T) {
+	c := ioc.NewContainer()
+	database := config.Default().Database
+	database.URL = ""
+
+	_, err := Connect(&c, database)
+	assert.ErrorContains(t, err, "database.url", "It fails at once, rather than retrying to reach localhost")
+}
+
+func TestIsMemory(t *testing.T) {
+	assert.True(t, IsMemory("sqlite::memory:"))
+	assert.True(t, IsMemory("sqlite://file:blogger?mode=memory&cache=shared"))
+	assert.False(t, IsMemory("sqlite://blogger.db"))
+	assert.False(t, IsMemory("postgres://db/blogger"))
+}
+
+func TestConnectMemory(t *testing.T) {
+	c := ioc.NewContainer()
+	database := config.Default().Database
+	database.URL = "sqlite::memory:"
+
+	db, err := Connect(&c, database)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec("CREATE TABLE blogs (id INTEGER)").Error)
+
+	// Concurrent queries must all see the same database, rather than each
+	// open a connection to a new one.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, db.Exec("INSERT INTO blogs VALUES (1)").Error)
+		}()
+	}
+	wg.Wait()
+
+	var n int64
+	assert.NoError(t, db.Raw("SELECT COUNT(*) FROM blogs").Scan(&n).Error)
+	assert.Equal(t, int64(10), n)
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"time"
+
+	"example.com/m/v2/ioc"
+	metrics "example.com/m/v2/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "metrics:query_start"
+
+// instrument measures the duration of the queries run through db, exports the
+// statistics of its pool, labelled with name, and traces its queries (see
+// traceQueries).
+func instrument(c *ioc.IOC, db *gorm.DB, name string) error {
+	if err := traceQueries(c, db, name); err != nil {
+		return err
+	}
+
+	durations := metrics.Register(c.Metrics, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of database queries.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"database", "operation"}))
+
+	start := func(string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			db.InstanceSet(queryStartKey, time.Now())
+		}
+	}
+	observe := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			if t, ok := db.InstanceGet(queryStartKey); ok {
+				durations.WithLabelValues(name, operation).Observe(time.Since(t.(time.Time)).Seconds())
+			}
+		}
+	}
+	if err := aroundQueries(db, "metrics", start, observe); err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	metrics.Register(c.Metrics, collectors.NewDBStatsCollector(sqlDB, name))
+	return nil
+}
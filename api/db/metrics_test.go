@@ -0,0 +1,29 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"example.com/m/v2/ioc"
+)
+
+func TestInstrument(t *testing.T) {
+	c := ioc.NewContainer()
+	db := openSQLite(t)
+	require.NoError(t, instrument(&c, db, "primary"))
+
+	require.NoError(t, db.Exec("CREATE TABLE blogs (id INTEGER PRIMARY KEY)").Error)
+	var count int64
+	require.NoError(t, db.Table("blogs").Count(&count).Error)
+
+	n, err := testutil.GatherAndCount(c.Metrics, "blogger_db_query_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "Queries are measured by operation")
+
+	n, err = testutil.GatherAndCount(c.Metrics, "go_sql_open_connections")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n, "The statistics of the pool are exported")
+}
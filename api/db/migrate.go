@@ -0,0 +1,322 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example.com/m/v2/ioc"
+	"gorm.io/gorm"
+)
+
+// The migrations are embedded in the binary, so that it can migrate the
+// database without the `migrate` CLI. SQLite has its own variants of them.
+//
+//go:embed migrations/*.sql migrations/sqlite/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID identifies the PostgreSQL advisory lock held while
+// migrating, so that replicas starting together migrate one at a time.
+const migrationLockID = 726_185_030
+
+// Migration is a version of the schema, read from
+// `<version>_<name>.up.sql` and `<version>_<name>.down.sql`.
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration is applied.
+type MigrationStatus struct {
+	Version uint   `json:"version" xml:"version" yaml:"version"`
+	Name    string `json:"name" xml:"name" yaml:"name"`
+	Applied bool   `json:"applied" xml:"applied" yaml:"applied"`
+}
+
+// ErrDirty is returned when a migration of the `migrate` CLI failed halfway.
+// The schema must be repaired by hand, and then marked as migrated with Goto.
+var ErrDirty = errors.New("the schema is dirty")
+
+// Migrator applies the embedded migrations. It records the version in the
+// same `schema_migrations` table as the `migrate` CLI, so it takes over
+// databases migrated by it.
+type Migrator struct {
+	ioc        *ioc.IOC
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+}
+
+func NewMigrator(c *ioc.IOC, db *gorm.DB, dialect string) (*Migrator, error) {
+	dir := "migrations"
+	if dialect == DialectSQLite {
+		dir = "migrations/sqlite"
+	}
+
+	migrations, err := loadMigrations(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{ioc: c, db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint]*Migration)
+	for _, file := range files {
+		base := path.Base(file)
+		prefix, rest, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("%s: expected <version>_<name>.<up|down>.sql", file)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version)}
+			byVersion[uint(version)] = m
+		}
+		switch {
+		case strings.HasSuffix(rest, ".up.sql"):
+			m.Name, m.Up = strings.TrimSuffix(rest, ".up.sql"), string(data)
+		case strings.HasSuffix(rest, ".down.sql"):
+			m.Down = string(data)
+		default:
+			return nil, fmt.Errorf("%s: expected <version>_<name>.<up|down>.sql", file)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d of %s needs both an up and a down file", m.Version, dir)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Latest returns the version that the binary expects the schema to be at.
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the current version of the schema, which is 0 before the
+// first migration.
+func (m *Migrator) Version() (uint, error) {
+	return m.version(m.db)
+}
+
+// Status lists every migration, and whether it is applied.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	version, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		res = append(res, MigrationStatus{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Version <= version,
+		})
+	}
+	return res, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+	return m.Goto(m.Latest())
+}
+
+// Down reverts the last steps migrations.
+func (m *Migrator) Down(steps int) error {
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+
+	target := uint(0)
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if m.migrations[i].Version > version {
+			continue
+		}
+		if steps == 0 {
+			target = m.migrations[i].Version
+			break
+		}
+		steps--
+	}
+	return m.Goto(target)
+}
+
+// Goto migrates up or down to the given version. On a dirty schema, it only
+// records the version, as the `migrate force` command does.
+func (m *Migrator) Goto(target uint) error {
+	if target != 0 && m.find(target) < 0 {
+		return fmt.Errorf("there is no migration %d", target)
+	}
+
+	return m.locked(func(tx *gorm.DB) error {
+		version, err := m.version(tx)
+		if errors.Is(err, ErrDirty) {
+			m.ioc.Logger.Warn("Marking the dirty schema as version", target)
+			return m.setVersion(tx, target)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version && migration.Version <= target {
+				m.ioc.Logger.Info("Applying migration", migration.Version, migration.Name)
+				if err := m.apply(tx, migration.Up, migration.Version); err != nil {
+					return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+				}
+			}
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version <= version && migration.Version > target {
+				previous := uint(0)
+				if i > 0 {
+					previous = m.migrations[i-1].Version
+				}
+				m.ioc.Logger.Info("Reverting migration", migration.Version, migration.Name)
+				if err := m.apply(tx, migration.Down, previous); err != nil {
+					return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// CheckVersion returns an error unless the schema is at the Latest version.
+func (m *Migrator) CheckVersion() error {
+	version, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if version != m.Latest() {
+		return fmt.Errorf("the schema is at version %d, but this binary expects version %d; run `server migrate up`", version, m.Latest())
+	}
+	return nil
+}
+
+// CheckMigrated returns an error if the schema is behind the Latest version,
+// or dirty. Unlike CheckVersion, a newer schema passes, as the migrations of
+// a newer binary must keep the schema compatible with the running one during
+// a rolling deployment. It only reads the version, so it suits frequent health
+// checks.
+func (m *Migrator) CheckMigrated(ctx context.Context) error {
+	version, err := m.readVersion(m.db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	if version < m.Latest() {
+		return fmt.Errorf("the schema is at version %d, but this binary expects version %d; run `server migrate up`", version, m.Latest())
+	}
+	return nil
+}
+
+// apply runs a migration and records the new version in one transaction.
+func (m *Migrator) apply(db *gorm.DB, statements string, version uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(statements).Error; err != nil {
+			return err
+		}
+		return m.setVersion(tx, version)
+	})
+}
+
+func (m *Migrator) version(db *gorm.DB) (uint, error) {
+	if err := m.createTable(db); err != nil {
+		return 0, err
+	}
+	return m.readVersion(db)
+}
+
+// readVersion is version without creating the table of versions first.
+func (m *Migrator) readVersion(db *gorm.DB) (uint, error) {
+	var rows []struct {
+		Version int64
+		Dirty   bool
+	}
+	if err := db.Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&rows).Error; err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if rows[0].Dirty {
+		return uint(rows[0].Version), fmt.Errorf("%w at version %d", ErrDirty, rows[0].Version)
+	}
+	return uint(rows[0].Version), nil
+}
+
+// setVersion records the version like the `migrate` CLI: a single row, or no
+// row at all before the first migration.
+func (m *Migrator) setVersion(db *gorm.DB, version uint) error {
+	if err := db.Exec("DELETE FROM schema_migrations").Error; err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	return db.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)", version, false).Error
+}
+
+func (m *Migrator) createTable(db *gorm.DB) error {
+	return db.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)").Error
+}
+
+// locked runs fn on a single connection. On PostgreSQL, the connection holds
+// an advisory lock, which serializes migrations across replicas.
+func (m *Migrator) locked(fn func(tx *gorm.DB) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if m.dialect != DialectPostgres {
+			return fn(conn)
+		}
+
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockID).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", migrationLockID)
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) find(version uint) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
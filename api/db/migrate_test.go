@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/m/v2/ioc"
+)
+
+func TestMigrator(t *testing.T) {
+	c := ioc.NewContainer()
+	db := openSQLite(t)
+	m, err := NewMigrator(&c, db, DialectSQLite)
+	assert.NoError(t, err)
+
+	assert.Error(t, m.CheckVersion())
+	assert.NoError(t, m.Up())
+	assert.NoError(t, m.CheckVersion())
+	assert.NoError(t, m.CheckMigrated(context.Background()))
+	assert.NoError(t, db.Exec("INSERT INTO blogs (title, body, fingerprint) VALUES ('title', 'body', 1)").Error)
+
+	assert.NoError(t, m.Down(1))
+	version, _ := m.Version()
+	assert.Equal(t, uint(3), version)
+	assert.Error(t, db.Exec("SELECT fingerprint FROM blogs").Error, "The column is dropped")
+	assert.Error(t, m.CheckMigrated(context.Background()), "The schema is behind")
+
+	assert.NoError(t, m.Goto(m.Latest()))
+	status, _ := m.Status()
+	assert.Len(t, status, int(m.Latest()))
+	for _, s := range status {
+		assert.True(t, s.Applied, "migration %d", s.Version)
+	}
+
+	// A migration of the `migrate` CLI that failed halfway.
+	assert.NoError(t, db.Exec("UPDATE schema_migrations SET dirty = true").Error)
+	_, err = m.Version()
+	assert.ErrorIs(t, err, ErrDirty)
+	assert.NoError(t, m.Goto(2), "Goto only records the version of a dirty schema")
+	version, err = m.Version()
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+}
+
+// TestMigrationDialects enforces that every migration has a variant for each
+// dialect.
+func TestMigrationDialects(t *testing.T) {
+	postgres, err := loadMigrations(migrationFiles, "migrations")
+	assert.NoError(t, err)
+	sqlite, err := loadMigrations(migrationFiles, "migrations/sqlite")
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(postgres), len(sqlite))
+	for i := range postgres {
+		assert.Equal(t, postgres[i].Version, sqlite[i].Version)
+		assert.Equal(t, postgres[i].Name, sqlite[i].Name)
+	}
+}
+
+// TestCheckMigrated enforces that a schema migrated by a newer binary, as
+// during a rolling deployment, does not fail the readiness of older ones.
+func TestCheckMigrated(t *testing.T) {
+	c := ioc.NewContainer()
+	db := openSQLite(t)
+	m, err := NewMigrator(&c, db, DialectSQLite)
+	assert.NoError(t, err)
+	assert.NoError(t, m.Up())
+
+	assert.NoError(t, db.Exec("UPDATE schema_migrations SET version = ?", m.Latest()+1).Error)
+	assert.Error(t, m.CheckVersion())
+	assert.NoError(t, m.CheckMigrated(context.Background()))
+
+	assert.NoError(t, db.Exec("UPDATE schema_migrations SET dirty = true").Error)
+	assert.ErrorIs(t, m.CheckMigrated(context.Background()), ErrDirty)
+}
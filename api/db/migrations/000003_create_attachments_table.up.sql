@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS attachments(
+   id serial PRIMARY KEY,
+   blog_id INTEGER NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+   filename VARCHAR (255) NOT NULL,
+   content_type VARCHAR (100) NOT NULL,
+   size BIGINT NOT NULL,
+   checksum CHAR (64) NOT NULL,
+   created_at TIMESTAMPTZ,
+   updated_at TIMESTAMPTZ,
+   deleted_at TIMESTAMPTZ,
+   UNIQUE (blog_id, checksum)
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS users(
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   username VARCHAR (50) UNIQUE NOT NULL,
+   password VARCHAR (50) NOT NULL,
+   email VARCHAR (300) UNIQUE NOT NULL,
+   created_at DATETIME,
+   updated_at DATETIME,
+   deleted_at DATETIME
+);
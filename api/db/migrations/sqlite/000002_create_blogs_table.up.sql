@@ -0,0 +1,10 @@
+-- SQLite does not enforce the length of VARCHAR columns; the API validates the
+-- length of titles itself.
+CREATE TABLE IF NOT EXISTS blogs(
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   title VARCHAR (50) UNIQUE NOT NULL,
+   body TEXT NOT NULL,
+   created_at DATETIME,
+   updated_at DATETIME,
+   deleted_at DATETIME
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS attachments(
+   id INTEGER PRIMARY KEY AUTOINCREMENT,
+   blog_id INTEGER NOT NULL REFERENCES blogs (id) ON DELETE CASCADE,
+   filename VARCHAR (255) NOT NULL,
+   content_type VARCHAR (100) NOT NULL,
+   size BIGINT NOT NULL,
+   checksum CHAR (64) NOT NULL,
+   created_at DATETIME,
+   updated_at DATETIME,
+   deleted_at DATETIME,
+   UNIQUE (blog_id, checksum)
+);
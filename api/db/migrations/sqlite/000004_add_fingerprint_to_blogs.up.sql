@@ -0,0 +1,3 @@
+-- SimHash of the blog's body, used to detect near-duplicate posts. Blogs
+-- created before this migration are given one when the server next starts.
+ALTER TABLE blogs ADD COLUMN fingerprint BIGINT;
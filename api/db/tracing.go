@@ -0,0 +1,62 @@
+package database
+
+import (
+	"errors"
+
+	"example.com/m/v2/ioc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const querySpanKey = "tracing:query_span"
+
+// traceQueries starts a span for every query run through db, as a child of
+// the span in the context of the query, if any. Queries outside of a trace,
+// such as those of background workers, are not traced.
+//
+// The span records the SQL with placeholders rather than the values bound to
+// them, which may be personal data.
+func traceQueries(c *ioc.IOC, db *gorm.DB, name string) error {
+	tracer := c.Tracing.Tracer("example.com/m/v2/db")
+	system := db.Dialector.Name()
+
+	start := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			ctx := db.Statement.Context
+			if !trace.SpanContextFromContext(ctx).IsValid() {
+				return
+			}
+			ctx, span := tracer.Start(ctx, "gorm."+operation, trace.WithSpanKind(trace.SpanKindClient))
+			db.Statement.Context = ctx
+			db.InstanceSet(querySpanKey, span)
+		}
+	}
+	end := func(db *gorm.DB) {
+		v, ok := db.InstanceGet(querySpanKey)
+		if !ok {
+			return
+		}
+		span := v.(trace.Span)
+		defer span.End()
+
+		span.SetAttributes(
+			semconv.DBSystemKey.String(system),
+			semconv.DBStatement(db.Statement.SQL.String()),
+			attribute.String("db.instance", name),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if db.Statement.Table != "" {
+			span.SetAttributes(semconv.DBSQLTable(db.Statement.Table))
+		}
+		// A missing row is an answer, not a failure of the query.
+		if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	return aroundQueries(db, "tracing", start, func(string) func(*gorm.DB) { return end })
+}
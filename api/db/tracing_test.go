@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+
+	"example.com/m/v2/ioc"
+)
+
+func TestTraceQueries(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	c := ioc.NewContainer()
+	c.Tracing = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	db := openSQLite(t)
+	require.NoError(t, traceQueries(&c, db, "primary"))
+
+	require.NoError(t, db.Exec("CREATE TABLE blogs (id INTEGER PRIMARY KEY, title TEXT)").Error)
+	assert.Empty(t, recorder.Ended(), "Queries outside of a trace are not traced")
+
+	ctx, parent := c.Tracing.Tracer("test").Start(context.Background(), "POST /blogs/")
+	require.NoError(t, db.WithContext(ctx).Exec("INSERT INTO blogs (title) VALUES (?)", "a secret title").Error)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	query := spans[0]
+	assert.Equal(t, "gorm.raw", query.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+
+	var statement string
+	for _, attr := range query.Attributes() {
+		if attr.Key == semconv.DBStatementKey {
+			statement = attr.Value.AsString()
+		}
+	}
+	assert.Equal(t, "INSERT INTO blogs (title) VALUES (?)", statement, "The values bound to the query are left out")
+}
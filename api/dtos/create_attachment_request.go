@@ -0,0 +1,11 @@
+package dtos
+
+import "io"
+
+// CreateAttachmentRequest is built by the controller from a multipart upload,
+// so that the service does not depend on the HTTP transport.
+type CreateAttachmentRequest struct {
+	BlogID   string
+	Filename string
+	Content  io.Reader
+}
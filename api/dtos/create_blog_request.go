@@ -3,11 +3,21 @@ package dtos
 // This struct decouples the HTTP payload from the internal model structure.
 // This feels like duplication, but is intentional.
 type CreateBlogRequest struct {
-	Title string `json:"title" binding:"required"`
-	Body  string `json:"body" binding:"required"`
+	Title string `json:"title" xml:"title" yaml:"title"`
+	Body  string `json:"body" xml:"body" yaml:"body"`
 }
 
 type UpdateBlogRequest struct {
-	Title string `json:"title" binding:"required"`
-	Body  string `json:"body" binding:"required"`
+	Title string `json:"title" xml:"title" yaml:"title"`
+	Body  string `json:"body" xml:"body" yaml:"body"`
+}
+
+// Validate trims the title and body, then checks that they are present and
+// that the title fits in the database.
+func (r *CreateBlogRequest) Validate() error {
+	return validateBlog(&r.Title, &r.Body)
+}
+
+func (r *UpdateBlogRequest) Validate() error {
+	return validateBlog(&r.Title, &r.Body)
 }
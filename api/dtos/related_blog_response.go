@@ -0,0 +1,12 @@
+package dtos
+
+import (
+	models "example.com/m/v2/models"
+)
+
+// RelatedBlogResponse is a blog along with how closely it relates to the blog
+// that was queried, from 0 (unrelated) upwards.
+type RelatedBlogResponse struct {
+	models.Blog `yaml:",inline"`
+	Score       float64 `json:"score" xml:"score" yaml:"score"`
+}
@@ -0,0 +1,8 @@
+package dtos
+
+// RouteResponse describes a route registered with the router.
+type RouteResponse struct {
+	Method  string `json:"method" xml:"method" yaml:"method"`
+	Path    string `json:"path" xml:"path" yaml:"path"`
+	Handler string `json:"handler" xml:"handler" yaml:"handler"`
+}
@@ -0,0 +1,77 @@
+package dtos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	apiErrors "example.com/m/v2/errors"
+)
+
+// Validator is implemented by requests that check their own fields once they
+// have been decoded. Validate may also normalize fields, such as trimming
+// whitespace, before checking them.
+type Validator interface {
+	Validate() error
+}
+
+// Validation rules reported to the client in apiErrors.FieldError.
+const (
+	RuleRequired  = "required"
+	RuleMaxLength = "max_length"
+)
+
+// MaxBlogTitleLength matches the `VARCHAR (50)` of the `blogs.title` column.
+const MaxBlogTitleLength = 50
+
+// validation collects every failed rule, so that the client can fix all of
+// them at once instead of one request at a time.
+type validation struct {
+	errs []apiErrors.FieldError
+}
+
+func (v *validation) required(field string, value string) bool {
+	if value == "" {
+		v.errs = append(v.errs, apiErrors.FieldError{
+			Field:   field,
+			Rule:    RuleRequired,
+			Message: fmt.Sprintf("%s is required", field),
+		})
+		return false
+	}
+	return true
+}
+
+// maxLength counts characters rather than bytes, like PostgreSQL does.
+func (v *validation) maxLength(field string, value string, max int) bool {
+	if utf8.RuneCountInString(value) > max {
+		v.errs = append(v.errs, apiErrors.FieldError{
+			Field:   field,
+			Rule:    RuleMaxLength,
+			Message: fmt.Sprintf("%s must be at most %d characters", field, max),
+			Params:  map[string]string{"max": strconv.Itoa(max)},
+		})
+		return false
+	}
+	return true
+}
+
+func (v *validation) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return &apiErrors.ValidationError{Fields: v.errs}
+}
+
+func validateBlog(title *string, body *string) error {
+	*title = strings.TrimSpace(*title)
+	*body = strings.TrimSpace(*body)
+
+	v := &validation{}
+	if v.required("title", *title) {
+		v.maxLength("title", *title, MaxBlogTitleLength)
+	}
+	v.required("body", *body)
+	return v.err()
+}
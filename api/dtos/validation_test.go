@@ -0,0 +1,62 @@
+package dtos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apiErrors "example.com/m/v2/errors"
+)
+
+func TestValidateBlogRequest(t *testing.T) {
+	tests := [...]struct {
+		name     string
+		request  CreateBlogRequest
+		expected []apiErrors.FieldError
+	}{
+		{
+			"Valid",
+			CreateBlogRequest{Title: strings.Repeat("é", MaxBlogTitleLength), Body: "hello world!"},
+			nil,
+		},
+		{
+			"Missing",
+			CreateBlogRequest{Title: " \t", Body: ""},
+			[]apiErrors.FieldError{
+				{Field: "title", Rule: RuleRequired, Message: "title is required"},
+				{Field: "body", Rule: RuleRequired, Message: "body is required"},
+			},
+		},
+		{
+			"TitleTooLong",
+			CreateBlogRequest{Title: strings.Repeat("a", MaxBlogTitleLength+1), Body: "hello world!"},
+			[]apiErrors.FieldError{
+				{Field: "title", Rule: RuleMaxLength, Message: "title must be at most 50 characters", Params: map[string]string{"max": "50"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *apiErrors.ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.expected, validationErr.Fields)
+		})
+	}
+}
+
+func TestValidateTrims(t *testing.T) {
+	r := &UpdateBlogRequest{Title: "  my title\n", Body: "\thello world! "}
+
+	assert.NoError(t, r.Validate())
+	assert.Equal(t, "my title", r.Title)
+	assert.Equal(t, "hello world!", r.Body)
+}
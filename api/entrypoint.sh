@@ -1,10 +1,10 @@
 #!/bin/sh -eu
 
-# Run the migrations
-sleep 10
+# Run the migrations. The server retries until the database is up, and
+# replicas starting together take turns through an advisory lock.
 echo "Running migrations..."
-migrate -database "${POSTGRESQL_URL}" -path db/migrations up
+/root/server migrate up
 
 # Run the server
 echo "Starting server..."
-/root/server
+exec /root/server serve
@@ -0,0 +1,165 @@
+package errors
+
+//go:generate go run ../cmd/errordocs -out ../../docs
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Stable, machine-readable error codes. Clients should branch on these rather
+// than on the HTTP status or the message, which may change (or be localized).
+//
+// Codes are never renamed or removed once published. Resource-specific codes
+// are prefixed with the resource name, e.g. `blog.not_found`.
+const (
+	CodeInternal             = "internal"
+	CodeBadRequest           = "bad_request"
+	CodeNotFound             = "not_found"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeConflict             = "conflict"
+	CodeConcurrentUpdate     = "concurrent_update"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeValidationFailed     = "validation_failed"
+	CodeValueTooLong         = "value_too_long"
+	CodeValueOutOfRange      = "value_out_of_range"
+	CodeInvalidFormat        = "invalid_format"
+	CodeNotImplemented       = "not_implemented"
+
+	CodeBlogNotFound       = "blog.not_found"
+	CodeBlogTitleConflict  = "blog.title_conflict"
+	CodeBlogNearDuplicate  = "blog.near_duplicate"
+	CodeAttachmentNotFound = "attachment.not_found"
+)
+
+// CatalogEntry documents an error code.
+type CatalogEntry struct {
+	Code        string `json:"code" xml:"code" yaml:"code"`
+	Status      int    `json:"status" xml:"status" yaml:"status"`
+	Description string `json:"description" xml:"description" yaml:"description"`
+}
+
+// Catalog lists every error code that the API may return. It is served at
+// `GET /errors`, and docs/errors.md and docs/errors.json are generated from
+// it with `go generate ./errors`.
+var Catalog = []CatalogEntry{
+	{CodeInternal, http.StatusInternalServerError, "An unexpected error occurred on the server."},
+	{CodeBadRequest, http.StatusBadRequest, "The request is malformed, e.g. its body cannot be decoded."},
+	{CodeNotFound, http.StatusNotFound, "The requested resource does not exist."},
+	{CodeNotAcceptable, http.StatusNotAcceptable, "None of the media types in the `Accept` header can be produced."},
+	{CodeConflict, http.StatusConflict, "The request conflicts with the current state of a resource."},
+	{CodeConcurrentUpdate, http.StatusConflict, "The request conflicted with a concurrent request, and may be retried."},
+	{CodePayloadTooLarge, http.StatusRequestEntityTooLarge, "The request body, or an uploaded file, is too large."},
+	{CodeUnsupportedMediaType, http.StatusUnsupportedMediaType, "The `Content-Type` of the request, or of an uploaded file, is not supported."},
+	{CodeValidationFailed, http.StatusUnprocessableEntity, "One or more fields are invalid. They are listed in `errors`."},
+	{CodeValueTooLong, http.StatusUnprocessableEntity, "A value is longer than allowed."},
+	{CodeValueOutOfRange, http.StatusUnprocessableEntity, "A number is outside of the allowed range."},
+	{CodeInvalidFormat, http.StatusBadRequest, "A value does not have the expected format."},
+	{CodeNotImplemented, http.StatusNotImplemented, "The endpoint exists, but is not implemented."},
+	{CodeBlogNotFound, http.StatusNotFound, "The blog does not exist."},
+	{CodeBlogTitleConflict, http.StatusConflict, "Another blog already has this title."},
+	{CodeBlogNearDuplicate, http.StatusConflict, "The blog is too similar to an existing blog, identified by `conflicting_id`."},
+	{CodeAttachmentNotFound, http.StatusNotFound, "The attachment does not exist."},
+}
+
+var catalogIndex = func() map[string]CatalogEntry {
+	m := make(map[string]CatalogEntry, len(Catalog))
+	for _, entry := range Catalog {
+		m[entry.Code] = entry
+	}
+	return m
+}()
+
+// InCatalog reports whether code is a documented error code.
+func InCatalog(code string) bool {
+	_, ok := catalogIndex[code]
+	return ok
+}
+
+// codeForStatus is the generic error code of an HTTP status, used when a
+// mapper does not set a more specific code.
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusNotAcceptable:
+		return CodeNotAcceptable
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	}
+	return CodeInternal
+}
+
+type resourceError struct {
+	resource string
+	err      error
+}
+
+func (e *resourceError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resourceError) Unwrap() error {
+	return e.err
+}
+
+// WithResource records which resource err relates to, such as "blog". This
+// refines generic error codes into resource-specific ones where the catalog
+// has them, e.g. `not_found` into `blog.not_found`.
+func WithResource(resource string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &resourceError{resource: resource, err: err}
+}
+
+// resolveCode picks the most specific code for the error that is in the
+// catalog. Every APIError thus carries a documented code.
+func resolveCode(a APIError) APIError {
+	var resourceErr *resourceError
+	if errors.As(a.err, &resourceErr) {
+		if code := resourceErr.resource + "." + a.errorCode; InCatalog(code) {
+			a.errorCode = code
+			return a
+		}
+	}
+
+	if !InCatalog(a.errorCode) {
+		a.errorCode = codeForStatus(a.Code)
+	}
+	return a
+}
+
+// Markdown renders the catalog as a markdown table.
+func Markdown() string {
+	entries := make([]CatalogEntry, len(Catalog))
+	copy(entries, Catalog)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Code < entries[j].Code
+	})
+
+	var b strings.Builder
+	b.WriteString("# Error Codes\n\n")
+	b.WriteString("<!-- Code generated by `go generate ./errors`. DO NOT EDIT. -->\n\n")
+	b.WriteString("Every error response carries one of the following codes in `error_code`.\n\n")
+	b.WriteString("| Code | Status | Description |\n")
+	b.WriteString("| ---- | ------ | ----------- |\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "| `%s` | %d %s | %s |\n", entry.Code, entry.Status, http.StatusText(entry.Status), entry.Description)
+	}
+	return b.String()
+}
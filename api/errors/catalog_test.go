@@ -0,0 +1,60 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCatalogIsConsistent(t *testing.T) {
+	seen := map[string]bool{}
+	for _, entry := range Catalog {
+		assert.False(t, seen[entry.Code], "%s is listed twice", entry.Code)
+		assert.NotEmpty(t, entry.Description, "%s is documented", entry.Code)
+		assert.NotEmpty(t, http.StatusText(entry.Status), "%s has a valid status", entry.Code)
+		seen[entry.Code] = true
+	}
+
+	for _, status := range []int{400, 404, 406, 409, 413, 415, 422, 500, 501, 503} {
+		assert.True(t, InCatalog(codeForStatus(status)), "the generic code of %d is in the catalog", status)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := [...]struct {
+		name string
+		err  error
+		code string
+	}{
+		{"Unknown", errors.New("unknown"), CodeInternal},
+		{"NotFound", gorm.ErrRecordNotFound, CodeNotFound},
+		{"BlogNotFound", WithResource("blog", gorm.ErrRecordNotFound), CodeBlogNotFound},
+		{"UnknownResource", WithResource("comment", gorm.ErrRecordNotFound), CodeNotFound},
+		{
+			"BlogTitleConflict",
+			WithResource("blog", &pgconn.PgError{Code: DUPLICATED_KEY, TableName: "blogs", ConstraintName: "blogs_title_key"}),
+			CodeBlogTitleConflict,
+		},
+		{
+			"UndocumentedConflict",
+			&pgconn.PgError{Code: DUPLICATED_KEY, TableName: "users", ConstraintName: "users_email_key"},
+			CodeConflict,
+		},
+		{"NearDuplicate", WithResource("blog", &NearDuplicateError{ConflictingID: 1}), CodeBlogNearDuplicate},
+		{"Deadlock", &pgconn.PgError{Code: DEADLOCK_DETECTED}, CodeConcurrentUpdate},
+		{"Validation", &ValidationError{}, CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewAPIError(tt.err)
+
+			assert.Equal(t, tt.code, result.GetErrorCode())
+			assert.True(t, InCatalog(result.GetErrorCode()))
+		})
+	}
+}
@@ -2,24 +2,65 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+)
 
-	// "gorm.io/driver/postgres" provides and uses this pg driver. Gorm's
-	// primitive error `ErrDuplicatedKey` does not appear to return true
-	// during type checking ( postgres 'v1.5.0' (gorm.io/gorm v1.25.1)),
-	// thus the impl below...
-	// See: https://github.com/go-gorm/gorm/issues/4135
+var IsNotImplementedError = errors.New("Not Implemented")
+var IsBadRequestError = errors.New("Bad Request")
+var IsNotAcceptableError = errors.New("Not Acceptable")
+var IsUnsupportedMediaTypeError = errors.New("Unsupported Media Type")
+var IsPayloadTooLargeError = errors.New("Payload Too Large")
+
+// NearDuplicateError is returned when a blog is too similar to an existing
+// one, which is identified by ConflictingID.
+type NearDuplicateError struct {
+	ConflictingID uint
+	Similarity    float64
+}
 
-	"github.com/jackc/pgx/v5/pgconn"
-	"gorm.io/gorm"
-)
+func (e *NearDuplicateError) Error() string {
+	return fmt.Sprintf("blog is a near-duplicate of blog %d (similarity %.2f)", e.ConflictingID, e.Similarity)
+}
 
-// https://www.postgresql.org/docs/current/errcodes-appendix.html
-const (
-	DUPLICATED_KEY = "23505"
-)
+// UniqueViolationError is returned by repositories that enforce unique
+// constraints themselves, rather than through a database driver. Column names
+// the constrained column(s), e.g. "title", like in PostgreSQL's default
+// `<table>_<column>_key` constraint names.
+type UniqueViolationError struct {
+	Table  string
+	Column string
+}
 
-var IsNotImplementedError = errors.New("Not Implemented")
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("duplicate key value violates unique constraint \"%s_%s_key\"", e.Table, e.Column)
+}
+
+// FieldError describes a single field of a request that failed validation.
+type FieldError struct {
+	Field   string `json:"field" xml:"field" yaml:"field"`
+	Rule    string `json:"rule" xml:"rule" yaml:"rule"`
+	Message string `json:"message" xml:"message" yaml:"message"`
+
+	// Params of the rule, such as "max" for `max_length`, which are used to
+	// localize the message. They are not rendered.
+	Params map[string]string `json:"-" xml:"-" yaml:"-"`
+}
+
+// ValidationError is returned when one or more fields of a request are
+// invalid. Every failed field is listed, not only the first one.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Message)
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
 
 // APIError is an error intended to be consumed by the error_handler middleware.
 // When an error occurs across any layer, it should contain all of the
@@ -27,42 +68,65 @@ var IsNotImplementedError = errors.New("Not Implemented")
 type APIError struct {
 	Code    int // An HTTP Status Code to represent this error.
 	err     error
-	message string // A clean log message for the client-side.
+	message string         // A clean log message for the client-side.
+	details map[string]any // Additional fields for the client-side, if any.
+	detail  string         // An explanation of this occurrence, if any.
+
+	// The locale key and parameters of detail, to localize it.
+	detailKey    string
+	detailParams map[string]string
+
+	// A stable code from the Catalog, for clients to branch on.
+	errorCode string
 }
 
+// NewAPIError maps err to an APIError using the mappers in the registry. If no
+// mapper recognises err, it becomes a 500 Internal Server Error.
 func NewAPIError(err error) APIError {
-	// Init a default error
-	result := APIError{
-		Code:    500,
-		err:     err,
-		message: http.StatusText(http.StatusInternalServerError),
+	if err != nil {
+		if result, ok := defaultRegistry.Map(err); ok {
+			return resolveCode(result)
+		}
 	}
 
-	// Then determine if, based on the message/type of error, the status needs
-	// to be changed.
-	return HandleDataNotFoundError(
-		HandleDuplicateError(
-			HandleIsNotImplementedError(result),
-		),
-	)
-}
-
-// isDuplicatedKeyError (and other error checking methods) directly check the
-// underlying database client used by gorm for an error code.
-func isDuplicatedKeyError(err error) bool {
-	var perr *pgconn.PgError
-	if errors.As(err, &perr) {
-		return perr.Code == DUPLICATED_KEY
+	return resolveCode(NewStatusError(http.StatusInternalServerError, err))
+}
+
+// NewStatusError returns an APIError with the given HTTP status code, and the
+// standard status text as its client-side message. Its error code is the
+// generic one for the status. It is intended for use by mappers.
+func NewStatusError(code int, err error) APIError {
+	return APIError{
+		Code:      code,
+		err:       err,
+		message:   http.StatusText(code),
+		errorCode: codeForStatus(code),
 	}
-	return false
 }
 
-func isNotFoundError(err error) bool {
-	return err == gorm.ErrRecordNotFound
+// WithCode returns a copy of the error with a more specific error code. The
+// code should be in the Catalog; otherwise the generic code for the HTTP
+// status is used instead.
+func (e APIError) WithCode(code string) APIError {
+	e.errorCode = code
+	return e
 }
 
-func isNotImplementedError(err error) bool {
-	return errors.Is(err, IsNotImplementedError)
+// WithDetails returns a copy of the error with additional fields for the
+// client-side, such as the ID of a conflicting resource.
+func (e APIError) WithDetails(details map[string]any) APIError {
+	e.details = details
+	return e
+}
+
+// WithDetail returns a copy of the error with an explanation of this
+// occurrence of the problem for the client-side. key names the explanation in
+// the locales, without its `detail.` prefix; its `{name}` placeholders are
+// replaced by params.
+func (e APIError) WithDetail(key string, params map[string]string) APIError {
+	e.detailKey, e.detailParams = key, params
+	e.detail, _ = messages.Message(DefaultLocale, "detail."+key, params)
+	return e
 }
 
 // Error returns the message attached to the err.
@@ -76,54 +140,17 @@ func (e *APIError) GetMessage() string {
 	return e.message
 }
 
-func (e *APIError) Unwrap() error {
-	return e.err
+// GetDetails returns additional fields that describe the error to the client,
+// such as the ID of a conflicting resource. It may be nil.
+func (e *APIError) GetDetails() map[string]any {
+	return e.details
 }
 
-func HandleDuplicateError(a APIError) APIError {
-	if a.err == nil {
-		return a
-	}
-
-	if isDuplicatedKeyError(a.err) {
-		return APIError{
-			Code:    http.StatusConflict,
-			err:     a.err,
-			message: http.StatusText(http.StatusConflict),
-		}
-	}
-
-	return a
-}
-
-func HandleDataNotFoundError(a APIError) APIError {
-	if a.err == nil {
-		return a
-	}
-
-	if isNotFoundError(a.err) {
-		return APIError{
-			Code:    http.StatusNotFound,
-			err:     a.err,
-			message: http.StatusText(http.StatusNotFound),
-		}
-	}
-
-	return a
+// GetErrorCode returns the stable code of the error, from the Catalog.
+func (e *APIError) GetErrorCode() string {
+	return e.errorCode
 }
 
-func HandleIsNotImplementedError(a APIError) APIError {
-	if a.err == nil {
-		return a
-	}
-
-	if isNotImplementedError(a.err) {
-		return APIError{
-			Code:    http.StatusNotImplemented,
-			err:     a.err,
-			message: http.StatusText(http.StatusNotImplemented),
-		}
-	}
-
-	return a
+func (e *APIError) Unwrap() error {
+	return e.err
 }
@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"embed"
+
+	"golang.org/x/text/language"
+
+	i18n "example.com/m/v2/pkg/i18n"
+)
+
+// DefaultLocale is used when the client does not ask for a supported locale,
+// and for messages that are missing from a locale.
+var DefaultLocale = language.English
+
+// Client-side messages are looked up by error code (`error.blog.not_found`),
+// by explanation (`detail.value_too_long`) and by validation rule
+// (`rule.max_length`).
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+var messages = func() *i18n.Bundle {
+	b, err := i18n.LoadBundle(locales, "locales", DefaultLocale)
+	if err != nil {
+		// The files are embedded, so this can only fail at development time.
+		panic(err)
+	}
+	return b
+}()
+
+// MatchLocale returns the supported locale that best satisfies an
+// `Accept-Language` header.
+func MatchLocale(acceptLanguage string) language.Tag {
+	return messages.Match(acceptLanguage)
+}
+
+// Localize returns a copy of the error whose client-side messages are in the
+// given locale. The wrapped error, which is what the server logs, is left in
+// English.
+func (e APIError) Localize(tag language.Tag) APIError {
+	if msg, ok := messages.Message(tag, "error."+e.errorCode, nil); ok {
+		e.message = msg
+	}
+	if e.detailKey != "" {
+		if msg, ok := messages.Message(tag, "detail."+e.detailKey, e.detailParams); ok {
+			e.detail = msg
+		}
+	}
+
+	if fields, ok := e.details["errors"].([]FieldError); ok {
+		localized := make([]FieldError, len(fields))
+		for i, f := range fields {
+			localized[i] = f.Localize(tag)
+		}
+
+		details := make(map[string]any, len(e.details))
+		for k, v := range e.details {
+			details[k] = v
+		}
+		details["errors"] = localized
+		e.details = details
+	}
+
+	return e
+}
+
+// Localize returns a copy of the field error with its message in the given
+// locale. Rules without a message of their own, such as those of `binding`
+// tags, get the generic `rule.failed` one.
+func (f FieldError) Localize(tag language.Tag) FieldError {
+	params := map[string]string{"field": f.Field, "rule": f.Rule}
+	for k, v := range f.Params {
+		params[k] = v
+	}
+
+	if msg, ok := messages.Message(tag, "rule."+f.Rule, params); ok {
+		f.Message = msg
+	} else if msg, ok := messages.Message(tag, "rule.failed", params); ok {
+		f.Message = msg
+	}
+	return f
+}
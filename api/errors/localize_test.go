@@ -0,0 +1,64 @@
+package errors
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+	"gorm.io/gorm"
+)
+
+// Every locale must translate every error code and validation rule that the
+// default locale does, so that clients never get a mix of languages.
+func TestLocalesAreComplete(t *testing.T) {
+	load := func(name string) map[string]string {
+		data, err := fs.ReadFile(locales, "locales/"+name)
+		assert.NoError(t, err)
+		m := map[string]string{}
+		assert.NoError(t, json.Unmarshal(data, &m))
+		return m
+	}
+
+	en := load("en.json")
+	for _, entry := range Catalog {
+		assert.Contains(t, en, "error."+entry.Code)
+	}
+
+	files, _ := fs.Glob(locales, "locales/*.json")
+	for _, file := range files {
+		m := load(file[len("locales/"):])
+		for key := range en {
+			assert.Contains(t, m, key, "%s is missing %s", file, key)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	err := &ValidationError{Fields: []FieldError{
+		{Field: "title", Rule: "max_length", Message: "title must be at most 50 characters", Params: map[string]string{"max": "50"}},
+		{Field: "title", Rule: "unknown", Message: "title is odd"},
+	}}
+	apiError := NewAPIError(WithResource("blog", err))
+
+	de := apiError.Localize(MatchLocale("de-AT, en;q=0.5"))
+	assert.Equal(t, "Ungültige Eingabe", de.GetMessage())
+	assert.Equal(t, []FieldError{
+		{Field: "title", Rule: "max_length", Message: "title darf höchstens 50 Zeichen lang sein", Params: map[string]string{"max": "50"}},
+		{Field: "title", Rule: "unknown", Message: "title erfüllt die Regel „unknown“ nicht"},
+	}, de.GetDetails()["errors"], "Rules without a message of their own get the generic one")
+	assert.Equal(t, "Ein oder mehrere Felder sind ungültig.", de.Problem("").Detail)
+
+	assert.Equal(t, "Unprocessable Entity", apiError.GetMessage(), "The original is not modified")
+	assert.Equal(t, err.Error(), de.Error(), "The server-side error stays in English")
+
+	en := NewAPIError(WithResource("blog", gorm.ErrRecordNotFound)).Localize(language.English)
+	assert.Equal(t, "Not Found", en.GetMessage(), "English messages are unchanged")
+
+	nearDuplicate := NewAPIError(WithResource("blog", &NearDuplicateError{ConflictingID: 7}))
+	assert.Equal(t, "The blog is too similar to blog 7.", nearDuplicate.Problem("").Detail)
+	fr := nearDuplicate.Localize(MatchLocale("fr"))
+	assert.Equal(t, "Le blog est trop similaire au blog 7.", fr.Problem("").Detail)
+
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Mappers for the errors defined by this application.
+func init() {
+	Register("not_implemented", PriorityHigh, Is(IsNotImplementedError, http.StatusNotImplemented))
+	Register("bad_request", PriorityHigh, Is(IsBadRequestError, http.StatusBadRequest))
+	Register("not_acceptable", PriorityHigh, Is(IsNotAcceptableError, http.StatusNotAcceptable))
+	Register("unsupported_media_type", PriorityHigh, Is(IsUnsupportedMediaTypeError, http.StatusUnsupportedMediaType))
+	Register("payload_too_large", PriorityHigh, Is(IsPayloadTooLargeError, http.StatusRequestEntityTooLarge))
+	Register("near_duplicate", PriorityHigh, mapNearDuplicateError)
+	Register("validation", PriorityHigh, mapValidationError)
+	Register("unique_violation", PriorityDefault, mapUniqueViolationError)
+	Register("gorm.not_found", PriorityDefault, Is(gorm.ErrRecordNotFound, http.StatusNotFound))
+}
+
+func mapNearDuplicateError(err error) (APIError, bool) {
+	var nearDuplicateErr *NearDuplicateError
+	if !errors.As(err, &nearDuplicateErr) {
+		return APIError{}, false
+	}
+
+	return NewStatusError(http.StatusConflict, err).
+		WithCode(CodeBlogNearDuplicate).
+		WithDetails(map[string]any{
+			"conflicting_id": nearDuplicateErr.ConflictingID,
+		}).
+		WithDetail("near_duplicate", map[string]string{"id": fmt.Sprint(nearDuplicateErr.ConflictingID)}), true
+}
+
+// mapUniqueViolationError names the conflicting column in the error code, in
+// the same way as mapDuplicateError.
+func mapUniqueViolationError(err error) (APIError, bool) {
+	var uniqueErr *UniqueViolationError
+	if !errors.As(err, &uniqueErr) {
+		return APIError{}, false
+	}
+	return NewStatusError(http.StatusConflict, err).WithCode(uniqueErr.Column + "_conflict"), true
+}
+
+func mapValidationError(err error) (APIError, bool) {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return APIError{}, false
+	}
+
+	return NewStatusError(http.StatusUnprocessableEntity, err).
+		WithDetails(map[string]any{
+			"errors": validationErr.Fields,
+		}).
+		WithDetail("validation_failed", nil), true
+}
@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	// "gorm.io/driver/postgres" provides and uses this pg driver. Gorm's
+	// primitive error `ErrDuplicatedKey` does not appear to return true
+	// during type checking ( postgres 'v1.5.0' (gorm.io/gorm v1.25.1)),
+	// thus the impl below...
+	// See: https://github.com/go-gorm/gorm/issues/4135
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	// Class 22 — Data Exception
+	DATA_EXCEPTION_CLASS         = "22"
+	STRING_DATA_RIGHT_TRUNCATION = "22001"
+	NUMERIC_VALUE_OUT_OF_RANGE   = "22003"
+	INVALID_TEXT_REPRESENTATION  = "22P02"
+
+	// Class 23 — Integrity Constraint Violation
+	INTEGRITY_CONSTRAINT_VIOLATION_CLASS = "23"
+	NOT_NULL_VIOLATION                   = "23502"
+	FOREIGN_KEY_VIOLATION                = "23503"
+	DUPLICATED_KEY                       = "23505"
+	CHECK_VIOLATION                      = "23514"
+
+	// Class 40 — Transaction Rollback
+	TRANSACTION_ROLLBACK_CLASS = "40"
+	SERIALIZATION_FAILURE      = "40001"
+	DEADLOCK_DETECTED          = "40P01"
+)
+
+// Mappers for the errors of the PostgreSQL driver used by gorm. Specific
+// SQLSTATE codes take precedence over the catch-all of their class.
+func init() {
+	Register("postgres.string_data_right_truncation", PriorityDefault, sqlState(STRING_DATA_RIGHT_TRUNCATION, http.StatusUnprocessableEntity, CodeValueTooLong, "value_too_long"))
+	Register("postgres.numeric_value_out_of_range", PriorityDefault, sqlState(NUMERIC_VALUE_OUT_OF_RANGE, http.StatusUnprocessableEntity, CodeValueOutOfRange, "value_out_of_range"))
+	Register("postgres.invalid_text_representation", PriorityDefault, sqlState(INVALID_TEXT_REPRESENTATION, http.StatusBadRequest, CodeInvalidFormat, "invalid_format"))
+	Register("postgres.not_null_violation", PriorityDefault, mapNotNullViolation)
+	Register("postgres.foreign_key_violation", PriorityDefault, sqlState(FOREIGN_KEY_VIOLATION, http.StatusConflict, CodeConflict, "broken_reference"))
+	Register("postgres.duplicated_key", PriorityDefault, mapDuplicateError)
+	Register("postgres.check_violation", PriorityDefault, sqlState(CHECK_VIOLATION, http.StatusUnprocessableEntity, CodeValidationFailed, "value_not_allowed"))
+	Register("postgres.serialization_failure", PriorityDefault, sqlState(SERIALIZATION_FAILURE, http.StatusConflict, CodeConcurrentUpdate, "concurrent_update"))
+	Register("postgres.deadlock_detected", PriorityDefault, sqlState(DEADLOCK_DETECTED, http.StatusConflict, CodeConcurrentUpdate, "concurrent_update"))
+
+	Register("postgres.data_exception", PriorityLow, sqlStateClass(DATA_EXCEPTION_CLASS, http.StatusBadRequest, CodeBadRequest))
+	Register("postgres.integrity_constraint_violation", PriorityLow, sqlStateClass(INTEGRITY_CONSTRAINT_VIOLATION_CLASS, http.StatusConflict, CodeConflict))
+	Register("postgres.transaction_rollback", PriorityLow, sqlStateClass(TRANSACTION_ROLLBACK_CLASS, http.StatusConflict, CodeConcurrentUpdate))
+}
+
+// isDuplicatedKeyError (and other error checking methods) directly check the
+// underlying database client used by gorm for an error code.
+func isDuplicatedKeyError(err error) bool {
+	perr, ok := asPgError(err)
+	return ok && perr.Code == DUPLICATED_KEY
+}
+
+// mapDuplicateError names the conflicting column in the error code, e.g.
+// `title_conflict`, which becomes `blog.title_conflict` for blogs. This relies
+// on PostgreSQL's default `<table>_<column>_key` name for unique constraints.
+func mapDuplicateError(err error) (APIError, bool) {
+	if !isDuplicatedKeyError(err) {
+		return APIError{}, false
+	}
+
+	result := NewStatusError(http.StatusConflict, err)
+	perr, _ := asPgError(err)
+	prefix, suffix := perr.TableName+"_", "_key"
+	if perr.TableName != "" && strings.HasPrefix(perr.ConstraintName, prefix) && strings.HasSuffix(perr.ConstraintName, suffix) {
+		column := strings.TrimSuffix(strings.TrimPrefix(perr.ConstraintName, prefix), suffix)
+		result = result.WithCode(column + "_conflict")
+	}
+	return result, true
+}
+
+func asPgError(err error) (*pgconn.PgError, bool) {
+	var perr *pgconn.PgError
+	if errors.As(err, &perr) {
+		return perr, true
+	}
+	return nil, false
+}
+
+// sqlState returns a mapper for a single SQLSTATE code. The message of detail
+// is shown to the client; the database's own message is not, since it may leak
+// the schema.
+func sqlState(code string, status int, errorCode string, detail string) Mapper {
+	return func(err error) (APIError, bool) {
+		perr, ok := asPgError(err)
+		if !ok || perr.Code != code {
+			return APIError{}, false
+		}
+		return NewStatusError(status, err).WithCode(errorCode).WithDetail(detail, nil), true
+	}
+}
+
+// sqlStateClass returns a mapper for every SQLSTATE code of a class, which
+// are the first two characters of the code.
+func sqlStateClass(class string, status int, errorCode string) Mapper {
+	return func(err error) (APIError, bool) {
+		perr, ok := asPgError(err)
+		if !ok || len(perr.Code) < 2 || perr.Code[:2] != class {
+			return APIError{}, false
+		}
+		return NewStatusError(status, err).WithCode(errorCode), true
+	}
+}
+
+// mapNotNullViolation reports the column as a missing field, in the same shape
+// as request validation errors.
+func mapNotNullViolation(err error) (APIError, bool) {
+	perr, ok := asPgError(err)
+	if !ok || perr.Code != NOT_NULL_VIOLATION {
+		return APIError{}, false
+	}
+
+	result := NewStatusError(http.StatusUnprocessableEntity, err).WithDetail("required_value_missing", nil)
+	if perr.ColumnName != "" {
+		result = result.WithDetails(map[string]any{
+			"errors": []FieldError{{
+				Field:   perr.ColumnName,
+				Rule:    "required",
+				Message: fmt.Sprintf("%s is required", perr.ColumnName),
+			}},
+		})
+	}
+	return result, true
+}
@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+)
+
+// Media types of RFC 7807 problem details.
+// See: https://www.rfc-editor.org/rfc/rfc7807
+const (
+	MIMEProblemJSON = "application/problem+json"
+	MIMEProblemXML  = "application/problem+xml"
+)
+
+// ProblemXMLNamespace is the namespace of problem details rendered as XML.
+const ProblemXMLNamespace = "urn:ietf:rfc:7807"
+
+// Problem is the RFC 7807 representation of an APIError. Extensions are
+// rendered as additional top-level members, next to the standard ones.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// Problem returns the RFC 7807 representation of the error. instance
+// identifies this occurrence of the problem, typically the request URI.
+func (e *APIError) Problem(instance string) Problem {
+	extensions := make(map[string]any, len(e.details)+1)
+	for k, v := range e.details {
+		extensions[k] = v
+	}
+	extensions["error_code"] = e.errorCode
+
+	return Problem{
+		// "about:blank" means that the problem has no additional semantics
+		// beyond those of the HTTP status code.
+		Type:       "about:blank",
+		Title:      e.message,
+		Status:     e.Code,
+		Detail:     e.detail,
+		Instance:   instance,
+		Extensions: extensions,
+	}
+}
+
+// Map flattens the problem into a single object, which is how it is
+// rendered by every format.
+func (p Problem) Map() map[string]any {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+
+	m["type"] = p.Type
+	m["title"] = p.Title
+	m["status"] = p.Status
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return m
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Map())
+}
+
+// MarshalXML follows the XML format described in appendix A of RFC 7807.
+func (p Problem) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{
+		Name: xml.Name{Space: ProblemXMLNamespace, Local: "problem"},
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	m := p.Map()
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := e.EncodeElement(m[k], xml.StartElement{Name: xml.Name{Local: k}}); err != nil {
+			return fmt.Errorf("problem member %q: %w", k, err)
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,110 @@
+package errors
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Mapper converts err into an APIError. It reports false if it does not
+// recognise err, in which case the next mapper is tried.
+type Mapper func(err error) (APIError, bool)
+
+// Priorities of the built-in mappers. Mappers with a higher priority are tried
+// first; mappers with the same priority are tried in the order in which they
+// were registered.
+const (
+	// PriorityHigh is for errors that the application defines itself, which
+	// are the most specific.
+	PriorityHigh = 100
+	// PriorityDefault is for specific errors of a library or driver, such as
+	// a single SQLSTATE code.
+	PriorityDefault = 50
+	// PriorityLow is for catch-alls, such as a whole SQLSTATE class.
+	PriorityLow = 10
+)
+
+type registration struct {
+	name     string
+	priority int
+	mapper   Mapper
+}
+
+// Registry is an ordered set of mappers. It is safe for concurrent use.
+type Registry struct {
+	mu            sync.RWMutex
+	registrations []registration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// defaultRegistry is used by NewAPIError. Packages add their own mappers to
+// it through Register, typically from an `init` function.
+var defaultRegistry = NewRegistry()
+
+// Register adds a mapper to the registry used by NewAPIError. name is used to
+// identify the mapper, and must be unique; registering a name twice replaces
+// the previous mapper.
+func Register(name string, priority int, m Mapper) {
+	defaultRegistry.Register(name, priority, m)
+}
+
+func (r *Registry) Register(name string, priority int, m Mapper) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.registrations {
+		if existing.name == name {
+			r.registrations = append(r.registrations[:i], r.registrations[i+1:]...)
+			break
+		}
+	}
+
+	r.registrations = append(r.registrations, registration{
+		name:     name,
+		priority: priority,
+		mapper:   m,
+	})
+	sort.SliceStable(r.registrations, func(i, j int) bool {
+		return r.registrations[i].priority > r.registrations[j].priority
+	})
+}
+
+// Map returns the result of the first mapper that recognises err.
+func (r *Registry) Map(err error) (APIError, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, reg := range r.registrations {
+		if result, ok := reg.mapper(err); ok {
+			return result, true
+		}
+	}
+	return APIError{}, false
+}
+
+// Names returns the names of the registered mappers, in the order in which
+// they are tried.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.registrations))
+	for _, reg := range r.registrations {
+		names = append(names, reg.name)
+	}
+	return names
+}
+
+// Is returns a mapper that maps errors matching target (see errors.Is) to the
+// given HTTP status code.
+func Is(target error, code int) Mapper {
+	return func(err error) (APIError, bool) {
+		if errors.Is(err, target) {
+			return NewStatusError(code, err), true
+		}
+		return APIError{}, false
+	}
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRegistryPriority(t *testing.T) {
+	target := errors.New("target")
+	r := NewRegistry()
+	r.Register("low", PriorityLow, Is(target, http.StatusTeapot))
+	r.Register("high", PriorityHigh, Is(target, http.StatusConflict))
+	r.Register("default", PriorityDefault, Is(target, http.StatusGone))
+
+	result, ok := r.Map(target)
+
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, result.Code)
+	assert.Equal(t, []string{"high", "default", "low"}, r.Names())
+
+	r.Register("high", PriorityHigh, Is(errors.New("other"), http.StatusConflict))
+	result, _ = r.Map(target)
+	assert.Equal(t, http.StatusGone, result.Code, "Registering a name again replaces the mapper")
+
+	_, ok = r.Map(errors.New("unknown"))
+	assert.False(t, ok)
+}
+
+func TestNewAPIError(t *testing.T) {
+	pgError := func(code string) error {
+		// gorm wraps driver errors, so mappers must unwrap them.
+		return fmt.Errorf("gorm: %w", &pgconn.PgError{Code: code, ColumnName: "title"})
+	}
+
+	tests := [...]struct {
+		name string
+		err  error
+		code int
+	}{
+		{"Unknown", errors.New("unknown"), http.StatusInternalServerError},
+		{"NotImplemented", IsNotImplementedError, http.StatusNotImplemented},
+		{"NotFound", gorm.ErrRecordNotFound, http.StatusNotFound},
+		{"Validation", &ValidationError{}, http.StatusUnprocessableEntity},
+		{"NearDuplicate", &NearDuplicateError{ConflictingID: 1}, http.StatusConflict},
+		{"StringTooLong", pgError(STRING_DATA_RIGHT_TRUNCATION), http.StatusUnprocessableEntity},
+		{"InvalidText", pgError(INVALID_TEXT_REPRESENTATION), http.StatusBadRequest},
+		{"OtherDataException", pgError("22012"), http.StatusBadRequest},
+		{"NotNull", pgError(NOT_NULL_VIOLATION), http.StatusUnprocessableEntity},
+		{"ForeignKey", pgError(FOREIGN_KEY_VIOLATION), http.StatusConflict},
+		{"Duplicate", pgError(DUPLICATED_KEY), http.StatusConflict},
+		{"OtherIntegrityViolation", pgError("23P01"), http.StatusConflict},
+		{"SerializationFailure", pgError(SERIALIZATION_FAILURE), http.StatusConflict},
+		{"OtherSQLState", pgError("53300"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			result := NewAPIError(tt.err)
+
+			assert.Equal(t, tt.code, result.Code)
+			assert.Equal(t, http.StatusText(tt.code), result.GetMessage())
+			assert.Equal(t, tt.err, result.Unwrap())
+			assert.True(t, InCatalog(result.GetErrorCode()), "%s is in the catalog", result.GetErrorCode())
+		})
+	}
+}
+
+func TestNotNullViolationFields(t *testing.T) {
+	result := NewAPIError(&pgconn.PgError{Code: NOT_NULL_VIOLATION, ColumnName: "body"})
+
+	assert.Equal(t, []FieldError{
+		{Field: "body", Rule: "required", Message: "body is required"},
+	}, result.GetDetails()["errors"])
+}
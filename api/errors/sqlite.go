@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	// The pure-Go driver used by "github.com/glebarez/sqlite". Unlike pgconn,
+	// it reports the constraint in the message only, e.g.
+	// `UNIQUE constraint failed: blogs.title (2067)`.
+	"github.com/glebarez/go-sqlite"
+)
+
+// https://www.sqlite.org/rescode.html#extrc
+const (
+	SQLITE_CONSTRAINT_FOREIGNKEY = 787
+	SQLITE_CONSTRAINT_NOTNULL    = 1299
+	SQLITE_CONSTRAINT_UNIQUE     = 2067
+)
+
+// Mappers for the errors of the SQLite driver, which match those of the
+// PostgreSQL driver for the same constraint violations.
+func init() {
+	Register("sqlite.unique", PriorityDefault, mapSQLiteUniqueError)
+	Register("sqlite.not_null", PriorityDefault, mapSQLiteNotNullError)
+	Register("sqlite.foreign_key", PriorityDefault, mapSQLiteForeignKeyError)
+	Register("sql.no_rows", PriorityDefault, Is(sql.ErrNoRows, http.StatusNotFound))
+}
+
+// isSQLiteUniqueError is the SQLite counterpart of isDuplicatedKeyError.
+func isSQLiteUniqueError(err error) bool {
+	serr, ok := asSQLiteError(err)
+	return ok && serr.Code() == SQLITE_CONSTRAINT_UNIQUE
+}
+
+// mapSQLiteUniqueError names the conflicting column(s) in the error code, like
+// mapDuplicateError, e.g. `title_conflict`.
+func mapSQLiteUniqueError(err error) (APIError, bool) {
+	if !isSQLiteUniqueError(err) {
+		return APIError{}, false
+	}
+
+	result := NewStatusError(http.StatusConflict, err)
+	if _, columns := sqliteConstraintColumns(err); len(columns) > 0 {
+		result = result.WithCode(strings.Join(columns, "_") + "_conflict")
+	}
+	return result, true
+}
+
+// mapSQLiteNotNullError reports the column as a missing field, like
+// mapNotNullViolation.
+func mapSQLiteNotNullError(err error) (APIError, bool) {
+	serr, ok := asSQLiteError(err)
+	if !ok || serr.Code() != SQLITE_CONSTRAINT_NOTNULL {
+		return APIError{}, false
+	}
+
+	result := NewStatusError(http.StatusUnprocessableEntity, err).WithDetail("required_value_missing", nil)
+	if _, columns := sqliteConstraintColumns(err); len(columns) == 1 {
+		result = result.WithDetails(map[string]any{
+			"errors": []FieldError{{
+				Field:   columns[0],
+				Rule:    "required",
+				Message: fmt.Sprintf("%s is required", columns[0]),
+			}},
+		})
+	}
+	return result, true
+}
+
+func mapSQLiteForeignKeyError(err error) (APIError, bool) {
+	serr, ok := asSQLiteError(err)
+	if !ok || serr.Code() != SQLITE_CONSTRAINT_FOREIGNKEY {
+		return APIError{}, false
+	}
+	return NewStatusError(http.StatusConflict, err).WithCode(CodeConflict).WithDetail("broken_reference", nil), true
+}
+
+func asSQLiteError(err error) (*sqlite.Error, bool) {
+	var serr *sqlite.Error
+	if errors.As(err, &serr) {
+		return serr, true
+	}
+	return nil, false
+}
+
+// sqliteConstraintColumns parses the table and columns out of a constraint
+// error, e.g. `UNIQUE constraint failed: attachments.blog_id,
+// attachments.checksum (2067)`.
+func sqliteConstraintColumns(err error) (table string, columns []string) {
+	msg := err.Error()
+	i := strings.Index(msg, "constraint failed: ")
+	if i < 0 {
+		return "", nil
+	}
+	msg = msg[i+len("constraint failed: "):]
+	if j := strings.LastIndex(msg, " ("); j >= 0 {
+		msg = msg[:j]
+	}
+
+	for _, qualified := range strings.Split(msg, ",") {
+		t, column, ok := strings.Cut(strings.TrimSpace(qualified), ".")
+		if !ok {
+			return "", nil
+		}
+		table = t
+		columns = append(columns, column)
+	}
+	return table, columns
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// TestSQLiteErrors maps the errors of a real, in-memory SQLite database, since
+// the driver only reports constraints in its messages.
+func TestSQLiteErrors(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:?_pragma=foreign_keys(1)"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(`CREATE TABLE blogs(id INTEGER PRIMARY KEY, title TEXT UNIQUE NOT NULL)`).Error)
+	assert.NoError(t, db.Exec(`CREATE TABLE attachments(id INTEGER PRIMARY KEY, blog_id INTEGER REFERENCES blogs (id), checksum TEXT, UNIQUE (blog_id, checksum))`).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO blogs(id, title) VALUES (1, 'title')`).Error)
+	assert.NoError(t, db.Exec(`INSERT INTO attachments(blog_id, checksum) VALUES (1, 'x')`).Error)
+
+	tests := [...]struct {
+		name      string
+		statement string
+		code      int
+		errorCode string
+	}{
+		{"Unique", `INSERT INTO blogs(title) VALUES ('title')`, http.StatusConflict, "title_conflict"},
+		{"CompositeUnique", `INSERT INTO attachments(blog_id, checksum) VALUES (1, 'x')`, http.StatusConflict, "blog_id_checksum_conflict"},
+		{"NotNull", `INSERT INTO blogs(title) VALUES (NULL)`, http.StatusUnprocessableEntity, CodeValidationFailed},
+		{"ForeignKey", `INSERT INTO attachments(blog_id, checksum) VALUES (2, 'y')`, http.StatusConflict, CodeConflict},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Exec(tt.statement).Error
+			assert.Error(t, err)
+
+			result, ok := defaultRegistry.Map(err)
+			assert.True(t, ok, "%v is mapped", err)
+			assert.Equal(t, tt.code, result.Code)
+			assert.Equal(t, tt.errorCode, result.GetErrorCode())
+		})
+	}
+
+	result := NewAPIError(WithResource("blog", db.Exec(`INSERT INTO blogs(title) VALUES ('title')`).Error))
+	assert.Equal(t, CodeBlogTitleConflict, result.GetErrorCode())
+}
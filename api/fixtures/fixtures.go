@@ -0,0 +1,129 @@
+// Package fixtures loads demo data into the repositories: the example blogs
+// embedded in the binary, fixture files in YAML or JSON, or blogs made up by
+// Generate. It is used by `server seed` and `server import`, and by tests that
+// need data to work with.
+package fixtures
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	dtos "example.com/m/v2/dtos"
+	"example.com/m/v2/ioc"
+	repositories "example.com/m/v2/repositories"
+	services "example.com/m/v2/services"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed data
+var data embed.FS
+
+// Set is the content of a fixture file. It only has blogs, since the API has
+// no users yet.
+type Set struct {
+	Blogs []dtos.CreateBlogRequest `json:"blogs" yaml:"blogs"`
+}
+
+// Result counts what Load did with the blogs of a Set.
+type Result struct {
+	Created int
+	Skipped int
+	Failed  int
+}
+
+// Embedded returns the example blogs embedded in the binary, read from every
+// fixture file under data/ in order of name.
+func Embedded() (*Set, error) {
+	files, err := fs.ReadDir(data, "data")
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{}
+	for _, file := range files {
+		b, err := fs.ReadFile(data, path.Join("data", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s, err := Parse(file.Name(), b)
+		if err != nil {
+			return nil, err
+		}
+		set.Blogs = append(set.Blogs, s.Blogs...)
+	}
+	return set, nil
+}
+
+// ReadFile reads a fixture file, in YAML or JSON according to its extension.
+func ReadFile(name string) (*Set, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(name, b)
+}
+
+// Parse decodes a fixture file named name, in YAML or JSON according to its
+// extension.
+func Parse(name string, b []byte) (*Set, error) {
+	set := &Set{}
+	var err error
+	switch ext := strings.ToLower(path.Ext(name)); ext {
+	case ".json":
+		err = json.Unmarshal(b, set)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, set)
+	default:
+		return nil, fmt.Errorf("%s: expected a .yaml, .yml or .json fixture file", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return set, nil
+}
+
+// Load creates the blogs of the set through the blog service, so that they are
+// validated and checked for duplicates as if they were posted to the API.
+// Blogs whose title is taken are skipped, so that loading is idempotent.
+// Failures are logged and counted, and do not stop the load; an error is only
+// returned if the existing blogs cannot be listed.
+func Load(ctx context.Context, c *ioc.IOC, s services.BlogService, r repositories.BlogRepository, set *Set) (Result, error) {
+	var res Result
+
+	existing, err := s.GetAll(ctx, r)
+	if err != nil {
+		return res, err
+	}
+	titles := make(map[string]bool, len(existing))
+	for _, blog := range existing {
+		titles[blog.Title] = true
+	}
+
+	for i := range set.Blogs {
+		request := set.Blogs[i]
+		if err := request.Validate(); err != nil {
+			c.Logger.Error("Blog", i, "is invalid:", err)
+			res.Failed++
+			continue
+		}
+		if titles[request.Title] {
+			res.Skipped++
+			continue
+		}
+
+		if _, err := s.Create(ctx, &request, r); err != nil {
+			c.Logger.Error("Failed to load blog", i, fmt.Sprintf("%q:", request.Title), err)
+			res.Failed++
+			continue
+		}
+		titles[request.Title] = true
+		res.Created++
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+package fixtures
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	dtos "example.com/m/v2/dtos"
+	"example.com/m/v2/ioc"
+	similarity "example.com/m/v2/pkg/similarity"
+	repositories "example.com/m/v2/repositories"
+	services "example.com/m/v2/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedded(t *testing.T) {
+	set, err := Embedded()
+	require.NoError(t, err)
+	require.NotEmpty(t, set.Blogs)
+
+	for _, blog := range set.Blogs {
+		assert.NoError(t, blog.Validate(), blog.Title)
+		assert.Contains(t, blog.Body, "\n\n", "%q should have several paragraphs", blog.Title)
+	}
+}
+
+func TestParse(t *testing.T) {
+	yamlSet, err := Parse("blogs.yaml", []byte("blogs:\n  - title: A\n    body: |-\n      One.\n\n      Two.\n"))
+	require.NoError(t, err)
+	jsonSet, err := Parse("blogs.json", []byte(`{"blogs": [{"title": "A", "body": "One.\n\nTwo."}]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []dtos.CreateBlogRequest{{Title: "A", Body: "One.\n\nTwo."}}, yamlSet.Blogs)
+	assert.Equal(t, yamlSet, jsonSet)
+
+	_, err = Parse("blogs.txt", nil)
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	assert.Equal(t, Generate(42, 20), Generate(42, 20), "the same seed should generate the same blogs")
+	assert.NotEqual(t, Generate(42, 20), Generate(43, 20))
+
+	// More blogs than there are titles, so that some are numbered.
+	set := Generate(1, 200)
+	require.Len(t, set.Blogs, 200)
+
+	titles := make(map[string]bool)
+	for _, blog := range set.Blogs {
+		assert.False(t, titles[blog.Title], "%q is generated twice", blog.Title)
+		titles[blog.Title] = true
+
+		assert.LessOrEqual(t, utf8.RuneCountInString(blog.Title), dtos.MaxBlogTitleLength, blog.Title)
+		assert.GreaterOrEqual(t, len(strings.Split(blog.Body, "\n\n")), 3, blog.Title)
+		assert.NotContains(t, blog.Body, "{", blog.Title)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	r := repositories.NewMemoryBlogRepository()
+	s := services.NewBlogService(&c, similarity.NewIndex(), services.DefaultDuplicatePolicy)
+
+	set := Generate(1, 50)
+	res, err := Load(ctx, &c, s, r, set)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 50}, res)
+
+	// Loading again is a no-op, and invalid blogs do not stop the others.
+	set.Blogs = append(set.Blogs, dtos.CreateBlogRequest{Title: "No body"}, dtos.CreateBlogRequest{Title: "New", Body: "A blog that is new."})
+	res, err = Load(ctx, &c, s, r, set)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Created: 1, Skipped: 50, Failed: 1}, res)
+
+	blogs, err := r.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, blogs, 51)
+}
@@ -0,0 +1,132 @@
+package fixtures
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	dtos "example.com/m/v2/dtos"
+)
+
+// The vocabulary of Generate.
+var (
+	topics = []string{
+		"Kubernetes", "PostgreSQL", "Go", "Docker", "Terraform", "Redis",
+		"CI Pipelines", "Observability", "Code Review", "On-Call", "gRPC",
+		"Nginx", "Helm", "Feature Flags", "Load Testing", "Microservices",
+	}
+
+	titleTemplates = []string{
+		"Lessons From a Year of %s",
+		"Why We Stopped Fighting %s",
+		"A Practical Guide to %s",
+		"%s in Production: What Broke",
+		"How We Scaled %s",
+		"Getting Started With %s",
+		"Debugging %s at 3 AM",
+		"What Nobody Tells You About %s",
+		"%s Without the Hype",
+		"Five Mistakes We Made With %s",
+	}
+
+	// Sentences may refer to the topic of the blog, and to the problem, team
+	// and number picked for it.
+	sentenceTemplates = []string{
+		"Our {topic} setup grew one workaround at a time, until {problem} became impossible to ignore.",
+		"The {team} team noticed it first, after {problem} woke them up {n} nights in a row.",
+		"Nobody had written down why {topic} was configured the way it was.",
+		"We started by measuring, since every opinion about {problem} contradicted the last one.",
+		"It took {n} attempts to reproduce the issue outside of production.",
+		"The fix itself was a handful of lines; finding where to put them took a week.",
+		"In hindsight, {problem} was a symptom rather than the cause.",
+		"We wrote a runbook, and the {team} team rehearsed it until it was boring.",
+		"Dashboards told us that something was wrong, but not what.",
+		"The documentation for {topic} covers the happy path well, and little else.",
+		"Rolling back was our first instinct, and it would have made things worse.",
+		"A {n}-line script replaced a wiki page that nobody trusted anymore.",
+		"We now review changes to {topic} with the same care as application code.",
+		"The {team} team still gets paged, but only for things that a person must fix.",
+		"Load tests caught {problem} before our users did, for once.",
+		"Most of the work was deleting configuration that no longer did anything.",
+		"Cost was never the goal, yet the bill dropped by {n} percent.",
+		"We could not have done it without the logs we had nearly turned off.",
+		"Every team that adopts {topic} seems to learn this lesson the hard way.",
+		"Small, reversible changes beat the grand rewrite that we had planned.",
+		"After {n} weeks, the graphs finally looked the way we had hoped.",
+		"The hardest part was agreeing on what done looked like.",
+		"We are not done: {problem} still shows up during the busiest hour of the week.",
+		"If you take one thing away, make it this: measure before you tune {topic}.",
+	}
+
+	problems = []string{
+		"flaky deploys", "slow queries", "memory leaks", "noisy alerts",
+		"connection storms", "cold starts", "config drift", "lock contention",
+		"retry storms", "disk pressure", "certificate expiry", "queue backlogs",
+	}
+
+	teams = []string{"platform", "payments", "search", "mobile", "data", "growth", "support tooling"}
+)
+
+// Generate makes up n blogs with realistic titles and multi-paragraph bodies.
+// The same seed always generates the same blogs, so that demos and tests are
+// reproducible. Titles are unique, and fit in dtos.MaxBlogTitleLength.
+func Generate(seed int64, n int) *Set {
+	rng := rand.New(rand.NewSource(seed))
+
+	type title struct{ text, topic string }
+	titles := make([]title, 0, len(topics)*len(titleTemplates))
+	for _, topic := range topics {
+		for _, template := range titleTemplates {
+			titles = append(titles, title{fmt.Sprintf(template, topic), topic})
+		}
+	}
+	rng.Shuffle(len(titles), func(i, j int) {
+		titles[i], titles[j] = titles[j], titles[i]
+	})
+
+	set := &Set{Blogs: make([]dtos.CreateBlogRequest, 0, n)}
+	for i := 0; len(set.Blogs) < n; i++ {
+		t := titles[i%len(titles)]
+		text := t.text
+		if part := i/len(titles) + 1; part > 1 {
+			text = fmt.Sprintf("%s (Part %d)", text, part)
+		}
+		if utf8.RuneCountInString(text) > dtos.MaxBlogTitleLength {
+			continue
+		}
+
+		set.Blogs = append(set.Blogs, dtos.CreateBlogRequest{
+			Title: text,
+			Body:  generateBody(rng, t.topic),
+		})
+	}
+	return set
+}
+
+// generateBody writes 3 to 5 paragraphs of 3 to 5 sentences each. Sentences
+// are drawn without replacement until the templates run out.
+func generateBody(rng *rand.Rand, topic string) string {
+	problem := problems[rng.Intn(len(problems))]
+	team := teams[rng.Intn(len(teams))]
+	order := rng.Perm(len(sentenceTemplates))
+	next := 0
+
+	paragraphs := make([]string, 3+rng.Intn(3))
+	for i := range paragraphs {
+		sentences := make([]string, 3+rng.Intn(3))
+		for j := range sentences {
+			template := sentenceTemplates[order[next%len(order)]]
+			next++
+			sentences[j] = strings.NewReplacer(
+				"{topic}", topic,
+				"{problem}", problem,
+				"{team}", team,
+				"{n}", strconv.Itoa(2+rng.Intn(40)),
+			).Replace(template)
+		}
+		paragraphs[i] = strings.Join(sentences, " ")
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
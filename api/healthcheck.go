@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	ioc "example.com/m/v2/ioc"
+)
+
+// setupHealthcheck implements `server healthcheck`, which fails unless the API
+// is ready, per `GET /healthz/ready`. It is meant for Docker's HEALTHCHECK and
+// exec probes, since the image has no curl; pass -url to check another probe.
+func setupHealthcheck(flags *flag.FlagSet) runFunc {
+	url := flags.String("url", "", "URL to check (default http://127.0.0.1:<server.port>/healthz/ready)")
+	timeout := flags.Duration("timeout", 3*time.Second, "how long to wait for a response")
+
+	return func(c *ioc.IOC, args []string) error {
+		if *url == "" {
+			*url = fmt.Sprintf("http://127.0.0.1:%d/healthz/ready", c.Config.Server.Port)
+		}
+
+		client := http.Client{Timeout: *timeout}
+		res, err := client.Get(*url)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("GET %s: %s", *url, res.Status)
+		}
+		return nil
+	}
+}
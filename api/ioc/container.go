@@ -1,11 +1,23 @@
 package ioc
 
 import (
+	config "example.com/m/v2/config"
+	health "example.com/m/v2/pkg/health"
 	logger "example.com/m/v2/pkg/logger"
+	metrics "example.com/m/v2/pkg/metrics"
+	storage "example.com/m/v2/pkg/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type IOC struct {
-	Logger *logger.DefaultLogger
+	Config    *config.Config
+	Logger    *logger.DefaultLogger
+	Storage   storage.Storage
+	Lifecycle *Lifecycle
+	Health    *health.Registry
+	Metrics   *prometheus.Registry
+	Tracing   trace.TracerProvider
 }
 
 // NewContainer returns a struct IOC (Inversion of Control) which contains
@@ -14,19 +26,35 @@ type IOC struct {
 // depend on some storage configuration, which could be configured here in one
 // place. We may consider adding our own abstraction layer between other new
 // dependencies (like we did the logger) that might need to be available in the
-// same manner. Another good example of things to put in here might be a
-// global configuration of the application.
+// same manner. The configuration of the application is in here too, loaded
+// once on startup, as are the lifecycle that components append their start and
+// stop hooks to, the registries that they register their health checks and
+// metrics in, and the provider of their tracers, which discards spans unless
+// tracing is set up.
 //
 // Note: the database connection is not included in here as to keep its
 // usage relevant to only the parts of the application that need it
 // (repositories).
+//
+// NewContainer uses the default configuration, e.g. for tests.
 func NewContainer() IOC {
+	return NewContainerWith(config.Default(), logger.NewDefaultLogger())
+}
+
+// NewContainerWith is NewContainer with the loaded configuration, and a logger
+// of the caller's choice, e.g. one that does not print to STDOUT.
+func NewContainerWith(cfg *config.Config, l *logger.DefaultLogger) IOC {
 	c := IOC{}
 
-	logger := logger.NewDefaultLogger()
-	logger.Info("Welcome to my blogger app!")
+	l.Info("Welcome to my blogger app!")
 
-	c.Logger = logger
+	c.Config = cfg
+	c.Logger = l
+	c.Storage = storage.NewLocalStorage(cfg.Attachments.StoragePath)
+	c.Health = health.NewRegistry()
+	c.Metrics = metrics.NewRegistry()
+	c.Tracing = trace.NewNoopTracerProvider()
+	c.Lifecycle = NewLifecycle(l, c.Health)
 
 	return c
 }
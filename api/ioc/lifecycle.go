@@ -0,0 +1,170 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	health "example.com/m/v2/pkg/health"
+	logger "example.com/m/v2/pkg/logger"
+)
+
+// Hook is a component with a lifecycle, such as a server or a background
+// worker. Either function may be nil.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle starts components in the order that they are appended, and stops
+// them in reverse, so that a component is stopped before those that it
+// depends on.
+//
+// Its state is reported by the "lifecycle" check, which fails the startup and
+// readiness probes until it is started, and readiness again once it stops.
+type Lifecycle struct {
+	logger *logger.DefaultLogger
+	health *health.Registry
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+
+	running  atomic.Bool
+	stopping atomic.Bool
+}
+
+func NewLifecycle(l *logger.DefaultLogger, h *health.Registry) *Lifecycle {
+	lc := &Lifecycle{logger: l, health: h}
+	h.Register(health.Check{
+		Name:   "lifecycle",
+		Probes: []health.Probe{health.Startup, health.Ready},
+		Run: func(context.Context) error {
+			switch {
+			case lc.Stopping():
+				return errors.New("shutting down")
+			case !lc.running.Load():
+				return errors.New("starting")
+			}
+			return nil
+		},
+	})
+	return lc
+}
+
+// Append adds a hook, which is started after those already appended.
+func (l *Lifecycle) Append(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, h)
+}
+
+// Go appends a background worker, which runs fn until it is stopped. Stopping
+// cancels the context of fn, and waits for it to return. Should fn return
+// before, the liveness probe fails, so that the process is restarted.
+func (l *Lifecycle) Go(name string, fn func(ctx context.Context)) {
+	var cancel context.CancelFunc
+	var exited atomic.Bool
+	done := make(chan struct{})
+
+	l.health.Register(health.Check{
+		Name:   "worker: " + name,
+		Probes: []health.Probe{health.Live},
+		Run: func(context.Context) error {
+			if exited.Load() {
+				return errors.New("exited unexpectedly")
+			}
+			return nil
+		},
+	})
+
+	l.Append(Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				fn(ctx)
+				if ctx.Err() == nil {
+					exited.Store(true)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+// Start starts every hook in order. If one fails, those already started are
+// stopped, and its error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.started < len(l.hooks) {
+		h := l.hooks[l.started]
+		if h.OnStart != nil {
+			l.logger.Info("Starting", h.Name)
+			if err := h.OnStart(ctx); err != nil {
+				l.stop(ctx)
+				return fmt.Errorf("%s: %w", h.Name, err)
+			}
+		}
+		l.started++
+	}
+	l.running.Store(true)
+	return nil
+}
+
+// Stop marks the lifecycle as stopping, and then stops the hooks that were
+// started, in reverse order. Every hook is stopped even if others fail, or ctx
+// expires.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stop(ctx)
+}
+
+func (l *Lifecycle) stop(ctx context.Context) error {
+	l.stopping.Store(true)
+	l.running.Store(false)
+
+	var failed []string
+	for ; l.started > 0; l.started-- {
+		h := l.hooks[l.started-1]
+		if h.OnStop == nil {
+			continue
+		}
+		l.logger.Info("Stopping", h.Name)
+		if err := h.OnStop(ctx); err != nil {
+			l.logger.Error("Failed to stop", h.Name+":", err)
+			failed = append(failed, h.Name)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to stop %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Stopping reports whether Stop was called, e.g. to fail readiness checks
+// while the server drains.
+func (l *Lifecycle) Stopping() bool {
+	return l.stopping.Load()
+}
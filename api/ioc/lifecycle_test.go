@@ -0,0 +1,87 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	health "example.com/m/v2/pkg/health"
+	logger "example.com/m/v2/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle(t *testing.T) {
+	var events []string
+	hook := func(name string, startErr error) Hook {
+		return Hook{
+			Name: name,
+			OnStart: func(context.Context) error {
+				events = append(events, "start "+name)
+				return startErr
+			},
+			OnStop: func(context.Context) error {
+				events = append(events, "stop "+name)
+				return nil
+			},
+		}
+	}
+
+	l := NewLifecycle(logger.NewDefaultLogger(), health.NewRegistry())
+	l.Append(hook("db", nil))
+	l.Append(hook("server", nil))
+
+	assert.NoError(t, l.Start(context.Background()))
+	assert.False(t, l.Stopping())
+	assert.NoError(t, l.Stop(context.Background()))
+	assert.True(t, l.Stopping())
+	assert.Equal(t, []string{"start db", "start server", "stop server", "stop db"}, events, "Hooks stop in reverse order")
+
+	events = nil
+	l = NewLifecycle(logger.NewDefaultLogger(), health.NewRegistry())
+	l.Append(hook("db", nil))
+	l.Append(hook("server", errors.New("port in use")))
+	l.Append(hook("worker", nil))
+
+	assert.ErrorContains(t, l.Start(context.Background()), "port in use")
+	assert.Equal(t, []string{"start db", "start server", "stop db"}, events, "Hooks started before a failure are stopped")
+}
+
+func TestLifecycleGo(t *testing.T) {
+	stopped := make(chan struct{})
+	l := NewLifecycle(logger.NewDefaultLogger(), health.NewRegistry())
+	l.Go("worker", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	assert.NoError(t, l.Start(context.Background()))
+	assert.NoError(t, l.Stop(context.Background()))
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Stop returned before the worker")
+	}
+}
+
+func TestLifecycleHealth(t *testing.T) {
+	ctx := context.Background()
+	h := health.NewRegistry()
+	l := NewLifecycle(logger.NewDefaultLogger(), h)
+	l.Go("crashing worker", func(context.Context) {})
+
+	assert.False(t, h.Run(ctx, health.Startup).Passed(), "Startup fails until the lifecycle is started")
+	assert.False(t, h.Run(ctx, health.Ready).Passed())
+	assert.True(t, h.Run(ctx, health.Live).Passed())
+
+	require.NoError(t, l.Start(ctx))
+	assert.True(t, h.Run(ctx, health.Startup).Passed())
+	assert.True(t, h.Run(ctx, health.Ready).Passed())
+	assert.Eventually(t, func() bool {
+		return !h.Run(ctx, health.Live).Passed()
+	}, time.Second, 10*time.Millisecond, "A worker that returns on its own fails liveness")
+
+	require.NoError(t, l.Stop(ctx))
+	assert.False(t, h.Run(ctx, health.Ready).Passed(), "Readiness fails once the lifecycle is stopping")
+}
@@ -1,44 +1,146 @@
 package main
 
 import (
-	"net/http"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
 
-	controllers "example.com/m/v2/controllers"
-	db "example.com/m/v2/db"
+	config "example.com/m/v2/config"
 	ioc "example.com/m/v2/ioc"
-	middleware "example.com/m/v2/middleware"
-	repositories "example.com/m/v2/repositories"
-	routers "example.com/m/v2/routers"
-	services "example.com/m/v2/services"
-	"github.com/gin-gonic/gin"
+	logger "example.com/m/v2/pkg/logger"
+	gormLogger "gorm.io/gorm/logger"
 )
 
+// runFunc runs a command, given the arguments left once its flags are parsed.
+type runFunc func(c *ioc.IOC, args []string) error
+
+// command is a subcommand of the server binary. Commands build their
+// dependencies through the app package, so that they share the wiring of the
+// HTTP layer.
+type command struct {
+	name    string
+	usage   string
+	summary string
+
+	// setup registers the flags of the command, besides those of the
+	// configuration, and returns the function that runs it.
+	setup func(flags *flag.FlagSet) runFunc
+}
+
+var commands = []command{
+	{"serve", "serve [-allow-schema-mismatch]", "serve the API (the default)", setupServe},
+	{"migrate", "migrate up | down [N] | status | goto N", "migrate the database", withoutFlags(runMigrate)},
+	{"seed", "seed [-file FILE | -generate N [-seed S]]", "create demo blogs, unless they exist", setupSeed},
+	{"export", "export [-o FILE]", "write every blog as JSON", setupExport},
+	{"import", "import [-i FILE]", "create the blogs read as JSON, unless they exist", setupImport},
+	{"routes", "routes", "list the routes of the API", withoutFlags(runRoutes)},
+	{"config", "config print", "print the configuration, with secrets redacted", withoutFlags(runConfig)},
+	{"healthcheck", "healthcheck [-url URL] [-timeout D]", "exit 0 if the API responds, e.g. for HEALTHCHECK", setupHealthcheck},
+}
+
+// main runs the command named by the first argument. Without one, or when the
+// first argument is a flag, it serves, as the binary did before it had
+// commands.
 func main() {
-	ioc := ioc.NewContainer()
-	db := db.Connect()
-	r := gin.Default()
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	// Init error handler
-	r.Use(middleware.ErrorHandler(&ioc))
+	if name == "help" {
+		usage(os.Stdout)
+		return
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
 
-	r.GET("/ping", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "pong",
-		})
+	// Only serve logs to STDOUT, since the output of the other commands may
+	// be piped elsewhere. This includes the queries logged by gorm.
+	out := os.Stderr
+	if cmd.name == "serve" {
+		out = os.Stdout
+	}
+	gormLogger.Default = gormLogger.New(log.New(out, "\r\n", log.LstdFlags), gormLogger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      gormLogger.Warn,
+		Colorful:      true,
 	})
+	l := logger.NewDefaultLoggerTo(out)
 
-	blogController := controllers.NewBlogController(
-		&ioc,
-		services.NewBlogService(
-			&ioc,
-		),
-		repositories.NewPostgreSQLBlogRepository(
-			&ioc,
-			db,
-		),
-	)
+	// Every command takes the flags of the configuration. The flag package
+	// reports its own errors.
+	flags := flag.NewFlagSet("server "+cmd.name, flag.ContinueOnError)
+	configFlags := config.RegisterFlags(flags)
+	run := cmd.setup(flags)
+	args, err := parse(flags, args)
+	if err != nil {
+		os.Exit(2)
+	}
+	cfg, err := configFlags.Load()
+	if err != nil {
+		exit(l, cmd.name, err)
+	}
+
+	ioc := ioc.NewContainerWith(cfg, l)
+	if err := run(&ioc, args); err != nil {
+		exit(l, cmd.name, err)
+	}
+}
 
-	routers.InitBlogRouter(r, blogController)
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: server <command> [-config FILE] [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-45s %s\n", cmd.usage, cmd.summary)
+	}
+}
+
+// parse parses flags among the arguments of a command, even after positional
+// ones, as in `server migrate up -config config.yaml`, and returns the
+// positional arguments.
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+}
+
+// withoutFlags sets up a command that only takes the flags of the
+// configuration.
+func withoutFlags(run runFunc) func(*flag.FlagSet) runFunc {
+	return func(*flag.FlagSet) runFunc {
+		return run
+	}
+}
 
-	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
+// exit stops the server when a command fails, with a message instead of a
+// stack trace.
+func exit(l *logger.DefaultLogger, name string, err error) {
+	l.Fatal("server "+name+":", err)
+	os.Exit(1)
 }
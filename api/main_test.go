@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	app "example.com/m/v2/app"
+	dtos "example.com/m/v2/dtos"
+	fixtures "example.com/m/v2/fixtures"
+	ioc "example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	storage "example.com/m/v2/pkg/storage"
+)
+
+func TestFindCommand(t *testing.T) {
+	cmd, ok := findCommand("migrate")
+	assert.True(t, ok)
+	assert.Equal(t, "migrate", cmd.name)
+
+	_, ok = findCommand("help")
+	assert.False(t, ok, "help is handled before commands are looked up")
+	_, ok = findCommand("")
+	assert.False(t, ok)
+
+	seen := map[string]bool{}
+	for _, cmd := range commands {
+		assert.False(t, seen[cmd.name], "%s is listed twice", cmd.name)
+		seen[cmd.name] = true
+	}
+}
+
+func TestParse(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	config := flags.String("config", "", "")
+	verbose := flags.Bool("v", false, "")
+
+	args, err := parse(flags, []string{"goto", "-config", "config.yaml", "3", "-v"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"goto", "3"}, args, "Flags are parsed after positional arguments")
+	assert.Equal(t, "config.yaml", *config)
+	assert.True(t, *verbose)
+
+	args, err = parse(flags, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, args)
+
+	_, err = parse(flags, []string{"up", "-unknown"})
+	assert.Error(t, err)
+}
+
+// TestExportImport exports the blogs of one memory backend, and imports them
+// into another.
+func TestExportImport(t *testing.T) {
+	newApp := func() *app.App {
+		c := ioc.NewContainer()
+		a, err := app.New(&c, app.Options{Backend: app.BackendMemory})
+		require.NoError(t, err)
+		return a
+	}
+
+	source := newApp()
+	require.NoError(t, load(source, &fixtures.Set{Blogs: []dtos.CreateBlogRequest{
+		{Title: "Docker", Body: "Containers share the kernel of their host."},
+		{Title: "Sourdough", Body: "Bread leavened by wild yeast and bacteria."},
+	}}))
+
+	var exported bytes.Buffer
+	require.NoError(t, export(source, &exported))
+	set, err := decode(bytes.NewReader(exported.Bytes()))
+	require.NoError(t, err)
+
+	target := newApp()
+	require.NoError(t, load(target, set))
+	require.NoError(t, load(target, set), "Importing twice skips the blogs that exist")
+
+	imported, err := target.BlogService.GetAll(context.Background(), target.BlogRepository)
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+	titles := map[string]string{}
+	for _, blog := range imported {
+		titles[blog.Title] = blog.Body
+	}
+	assert.Equal(t, map[string]string{
+		"Docker":    "Containers share the kernel of their host.",
+		"Sourdough": "Bread leavened by wild yeast and bacteria.",
+	}, titles)
+
+	_, err = decode(bytes.NewReader([]byte(`{"title": "not an array"}`)))
+	assert.ErrorContains(t, err, "JSON array")
+}
+
+// TestRouterCacheControl checks that the blog routes send the `Cache-Control`
+// headers of `http_cache`.
+func TestRouterCacheControl(t *testing.T) {
+	c := ioc.NewContainer()
+	c.Config.HTTPCache.Index = "max-age=60"
+	c.Config.HTTPCache.Words = ""
+	a, err := app.New(&c, app.Options{Backend: app.BackendMemory})
+	require.NoError(t, err)
+	_, err = a.BlogRepository.Create(context.Background(), &models.Blog{Title: "title", Body: "body"})
+	require.NoError(t, err)
+	r := newRouter(a)
+
+	for path, want := range map[string]string{
+		"/blogs/":        "max-age=60",
+		"/blogs/1":       "no-cache",
+		"/blogs/1/words": "",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, path)
+		assert.Equal(t, want, w.Header().Get("Cache-Control"), path)
+	}
+}
+
+// TestRouterAttachments uploads an attachment through the route nested in its
+// blog, and downloads it from its own.
+func TestRouterAttachments(t *testing.T) {
+	c := ioc.NewContainer()
+	c.Storage = storage.NewLocalStorage(t.TempDir())
+	a, err := app.New(&c, app.Options{Backend: app.BackendMemory})
+	require.NoError(t, err)
+	_, err = a.BlogRepository.Create(context.Background(), &models.Blog{Title: "title", Body: "body"})
+	require.NoError(t, err)
+	r := newRouter(a)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	gif := "GIF89a\x01\x00\x01\x00"
+	file, err := form.CreateFormFile("file", "pixel.gif")
+	require.NoError(t, err)
+	_, err = file.Write([]byte(gif))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/blogs/1/attachments/", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var attachment models.Attachment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &attachment))
+	assert.Equal(t, uint(1), attachment.BlogID, "The blog is read from the nested route")
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/attachments/"+strconv.Itoa(int(attachment.ID)), nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, gif, w.Body.String())
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// CacheControl sets the `Cache-Control` header to the given policy, such as
+// "no-cache" or "public, max-age=60". Responses are negotiated from the
+// `Accept` header, so caches are also told to key on it. An empty policy
+// leaves the header unset.
+func CacheControl(policy string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy != "" {
+			c.Header("Cache-Control", policy)
+		}
+		c.Header("Vary", "Accept")
+
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"example.com/m/v2/ioc"
+	metrics "example.com/m/v2/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels requests that match no route, so that scanners
+// probing random paths do not create a series per path.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts the requests, and measures their latency, by method, route
+// template (e.g. `/blogs/:id`) and status. It also tracks how many are in
+// flight.
+func Metrics(c *ioc.IOC) gin.HandlerFunc {
+	labels := []string{"method", "route", "status"}
+	requests := metrics.Register(c.Metrics, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests served.",
+	}, labels))
+	durations := metrics.Register(c.Metrics, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, labels))
+	inFlight := metrics.Register(c.Metrics, prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests being served.",
+	}))
+
+	return func(c *gin.Context) {
+		inFlight.Inc()
+		start := time.Now()
+
+		panicked := true
+		defer func() {
+			inFlight.Dec()
+			values := []string{c.Request.Method, routeTemplate(c), strconv.Itoa(responseStatus(c, panicked))}
+			requests.WithLabelValues(values...).Inc()
+			durations.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+		}()
+
+		c.Next()
+		panicked = false
+	}
+}
+
+// routeTemplate returns the template of the route that the request matched,
+// or unmatchedRoute.
+func routeTemplate(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return unmatchedRoute
+}
+
+// responseStatus returns the status of the response. A handler that panicked
+// has not written it yet; gin's Recovery middleware, which runs around this
+// one, answers with a 500.
+func responseStatus(c *gin.Context, panicked bool) int {
+	if panicked {
+		return http.StatusInternalServerError
+	}
+	return c.Writer.Status()
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"example.com/m/v2/ioc"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := ioc.NewContainer()
+	r := gin.New()
+	r.Use(gin.RecoveryWithWriter(io.Discard), Metrics(&c))
+	r.GET("/blogs/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	for _, path := range []string{"/blogs/1", "/blogs/2", "/nope", "/panic"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP blogger_http_requests_total Number of HTTP requests served.
+# TYPE blogger_http_requests_total counter
+blogger_http_requests_total{method="GET",route="/blogs/:id",status="200"} 2
+blogger_http_requests_total{method="GET",route="/panic",status="500"} 1
+blogger_http_requests_total{method="GET",route="unmatched",status="404"} 1
+# HELP blogger_http_requests_in_flight Number of HTTP requests being served.
+# TYPE blogger_http_requests_in_flight gauge
+blogger_http_requests_in_flight 0
+`
+	assert.NoError(t, testutil.GatherAndCompare(c.Metrics, strings.NewReader(expected), "blogger_http_requests_total", "blogger_http_requests_in_flight"),
+		"Requests are labelled by route template, and panics are counted")
+}
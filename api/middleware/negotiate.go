@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"example.com/m/v2/controllers"
+	apiErrors "example.com/m/v2/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// Negotiate rejects requests whose `Accept` header cannot be satisfied by any
+// of the formats offered by the controllers. This happens before the handler
+// runs so that, for example, a blog is not created only for the client to be
+// told that it cannot read the response.
+func Negotiate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if controllers.Negotiate(c) == "" {
+			controllers.HandleAPIError(c, apiErrors.IsNotAcceptableError)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"example.com/m/v2/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemDetails renders every error as RFC 7807 problem details when enabled,
+// regardless of the `Accept` header. When disabled, clients can still opt in
+// by accepting `application/problem+json`.
+func ProblemDetails(enabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if enabled {
+			c.Set(controllers.ProblemDetailsKey, true)
+		}
+
+		c.Next()
+	}
+}
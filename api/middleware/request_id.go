@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"example.com/m/v2/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request, so that a client can quote it
+// when reporting a problem, and so that it can be traced across services.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength guards against clients sending arbitrarily large IDs,
+// which end up in the logs.
+const maxRequestIDLength = 128
+
+// RequestID reuses the request ID sent by the client (or a proxy) if any, and
+// otherwise generates a new one. The ID is echoed in the response.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLength {
+			id = newRequestID()
+		}
+
+		c.Set(controllers.RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	// crypto/rand only fails if the OS is unable to provide randomness.
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
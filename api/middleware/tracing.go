@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"example.com/m/v2/controllers"
+	"example.com/m/v2/ioc"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a span for every request, named after its route template,
+// e.g. `GET /blogs/:id`. If the client sent a W3C `traceparent` header, the
+// span continues its trace. The span is in the context of the request, so
+// that the services and repositories that handle it add theirs as children.
+//
+// Requests that fail with a 5xx status, or whose handler panics, mark the
+// span as failed; those that fail with a 4xx status record their error
+// without doing so, as the server did what it should.
+func Tracing(c *ioc.IOC) gin.HandlerFunc {
+	tracer := c.Tracing.Tracer("example.com/m/v2/middleware")
+	propagator := propagation.TraceContext{}
+
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := routeTemplate(c)
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(c.Request.Method),
+				semconv.HTTPRoute(route),
+				// The query string is left out, as it may hold personal data.
+				semconv.HTTPTarget(c.Request.URL.Path),
+			),
+		)
+		panicked := true
+		defer func() {
+			status := responseStatus(c, panicked)
+			span.SetAttributes(semconv.HTTPStatusCode(status))
+			if id := controllers.GetRequestID(c); id != "" {
+				span.SetAttributes(attribute.String("http.request_id", id))
+			}
+			for _, err := range c.Errors {
+				span.RecordError(err.Err)
+			}
+			switch {
+			case panicked:
+				span.SetStatus(codes.Error, "panic")
+			case status >= http.StatusInternalServerError:
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			span.End()
+		}()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		panicked = false
+	}
+}
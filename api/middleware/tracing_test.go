@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	app "example.com/m/v2/app"
+	"example.com/m/v2/controllers"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+)
+
+// TestTracing follows a request from the trace of its caller down to the
+// queries that it runs, on SQLite.
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	c := ioc.NewContainer()
+	c.Tracing = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	c.Config.Database.URL = "sqlite://" + filepath.Join(t.TempDir(), "blogger.db")
+
+	a, err := app.New(&c, app.Options{AllowSchemaMismatch: true})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := a.DB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	migrator, err := app.NewMigrator(&c, a.DB)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up())
+	_, err = a.BlogRepository.Create(context.Background(), &models.Blog{Title: "title", Body: "body"})
+	require.NoError(t, err)
+	require.Empty(t, recorder.Ended(), "Nothing is traced outside of a request")
+
+	r := gin.New()
+	r.Use(Tracing(&c))
+	r.GET("/blogs/:id", controllers.NewBlogController(&c, a.BlogService, a.BlogRepository).Show)
+
+	req := httptest.NewRequest(http.MethodGet, "/blogs/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	request, service, query := spans["GET /blogs/:id"], spans["BlogService.GetByID"], spans["gorm.query"]
+	require.NotNil(t, request)
+	require.NotNil(t, service)
+	require.NotNil(t, query)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	callerID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	for _, span := range []sdktrace.ReadOnlySpan{request, service, query} {
+		assert.Equal(t, traceID, span.SpanContext().TraceID(), "%s continues the trace of the caller", span.Name())
+	}
+	assert.Equal(t, callerID, request.Parent().SpanID())
+	assert.True(t, request.Parent().IsRemote())
+	assert.Equal(t, request.SpanContext().SpanID(), service.Parent().SpanID())
+	assert.Equal(t, service.SpanContext().SpanID(), query.Parent().SpanID())
+}
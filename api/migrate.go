@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	app "example.com/m/v2/app"
+	db "example.com/m/v2/db"
+	ioc "example.com/m/v2/ioc"
+)
+
+const migrateUsage = "usage: server migrate up | down [N] | status | goto N"
+
+// runMigrate implements `server migrate`, which applies the migrations
+// embedded in the binary to the database at `database.url`.
+func runMigrate(c *ioc.IOC, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	gormDB, err := db.Connect(c, c.Config.Database)
+	if err != nil {
+		return err
+	}
+	migrator, err := app.NewMigrator(c, gormDB)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("down takes a number of migrations to revert, got %q", args[1])
+			}
+		}
+		return migrator.Down(steps)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("goto takes a version, got %q", args[1])
+		}
+		return migrator.Goto(uint(version))
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%06d %-32s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	}
+	return errors.New(migrateUsage)
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Attachment is the metadata of a file uploaded to a blog. The file itself is
+// kept in storage under its Checksum, so identical uploads share one object.
+type Attachment struct {
+	gorm.Model  `yaml:",inline"`
+	BlogID      uint   `json:"blog_id" xml:"blog_id" yaml:"blog_id"`
+	Filename    string `json:"filename" xml:"filename" yaml:"filename"`
+	ContentType string `json:"content_type" xml:"content_type" yaml:"content_type"`
+	Size        int64  `json:"size" xml:"size" yaml:"size"`
+	Checksum    string `json:"checksum" xml:"checksum" yaml:"checksum"`
+}
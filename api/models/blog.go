@@ -8,10 +8,18 @@ import (
 	utils "example.com/m/v2/pkg/utils"
 )
 
+// Note: the `xml` and `yaml` tags allow the model to be rendered in any of the
+// formats negotiated by the controllers. gorm.Model is inlined for YAML so that
+// its fields appear at the top level, as they do in JSON.
 type Blog struct {
-	gorm.Model
-	Title string `json:"title" binding:"required"`
-	Body  string `json:"body" binding:"required"`
+	gorm.Model `yaml:",inline"`
+	Title      string `json:"title" xml:"title" yaml:"title" binding:"required"`
+	Body       string `json:"body" xml:"body" yaml:"body" binding:"required"`
+
+	// Fingerprint is the SimHash of Body, stored as a signed integer since
+	// PostgreSQL has no unsigned 64-bit type, or nil if Body has no words. It
+	// is internal, and thus never rendered.
+	Fingerprint *int64 `json:"-" xml:"-" yaml:"-"`
 }
 
 func (b *Blog) GetWordCount() map[string]int {
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats counts the lookups of a cache since it was created.
+type Stats struct {
+	Hits      uint64 `json:"hits" xml:"hits" yaml:"hits"`
+	Misses    uint64 `json:"misses" xml:"misses" yaml:"misses"`
+	Evictions uint64 `json:"evictions" xml:"evictions" yaml:"evictions"`
+	Size      int    `json:"size" xml:"size" yaml:"size"`
+}
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// LRU is a thread-safe cache holding up to capacity entries. The least
+// recently used entry is evicted to make room for new ones, and entries
+// expire ttl after they are set.
+type LRU[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[K]*list.Element
+	order    *list.List // Most recently used first.
+	stats    Stats
+
+	// generation is bumped by every Delete and Purge. See SetIf.
+	generation uint64
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+func NewLRU[K comparable, V any](capacity int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[K]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value for key, and whether it was found and not expired.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		if c.now().Before(e.expires) {
+			c.order.MoveToFront(el)
+			c.stats.Hits++
+			return e.value, true
+		}
+		c.remove(el)
+	}
+
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// Set adds or replaces the value for key.
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+}
+
+// Generation returns the current generation of the cache, for SetIf.
+func (c *LRU[K, V]) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// SetIf is Set, unless a value was deleted or purged since Generation returned
+// generation. A value read from a store before the generation was taken may be
+// stale once another caller invalidated it, and must then not be cached. It
+// reports whether the value was set.
+func (c *LRU[K, V]) SetIf(key K, value V, generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generation != generation {
+		return false
+	}
+	c.set(key, value)
+	return true
+}
+
+// set must be called with the lock held.
+func (c *LRU[K, V]) set(key K, value V) {
+	if c.capacity <= 0 {
+		return
+	}
+
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	for c.order.Len() >= c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+	c.entries[key] = c.order.PushFront(&entry[K, V]{key, value, expires})
+}
+
+// Delete removes the value for key, if any.
+func (c *LRU[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Purge removes every value.
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+func (c *LRU[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	return stats
+}
+
+// remove must be called with the lock held.
+func (c *LRU[K, V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry[K, V]).key)
+}
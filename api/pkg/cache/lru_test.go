@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Now()
+	c := NewLRU[string, int](2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, _ = c.Get("a")
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok, "The least recently used entry is evicted")
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("c")
+	assert.False(t, ok, "Entries expire after the TTL")
+
+	assert.Equal(t, Stats{Hits: 2, Misses: 2, Evictions: 1, Size: 1}, c.Stats())
+
+	c.Purge()
+	assert.Equal(t, 0, c.Stats().Size)
+}
+
+func TestLRUSetIf(t *testing.T) {
+	c := NewLRU[string, int](2, time.Minute)
+
+	generation := c.Generation()
+	assert.True(t, c.SetIf("a", 1, generation))
+
+	generation = c.Generation()
+	c.Delete("b")
+	assert.False(t, c.SetIf("a", 2, generation), "A value read before a deletion may be stale")
+	v, _ := c.Get("a")
+	assert.Equal(t, 1, v)
+
+	generation = c.Generation()
+	c.Purge()
+	assert.False(t, c.SetIf("a", 2, generation))
+	assert.True(t, c.SetIf("a", 2, c.Generation()))
+}
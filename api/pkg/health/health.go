@@ -0,0 +1,188 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Probe is a question asked by the orchestrator. Each check answers one or
+// more of them.
+type Probe string
+
+const (
+	// Live fails when the process must be restarted, e.g. a background worker
+	// died. It must not depend on other services, or their outage would
+	// restart every replica.
+	Live Probe = "live"
+
+	// Ready fails while the process cannot serve requests, e.g. without a
+	// database, or while it drains on shutdown.
+	Ready Probe = "ready"
+
+	// Startup fails until the process has started.
+	Startup Probe = "startup"
+)
+
+// Statuses of a check and of a report.
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout is the timeout of checks that do not set their own.
+const DefaultTimeout = 2 * time.Second
+
+// Check is a named health check. Its result is cached for CacheFor, so that
+// frequent probes do not hammer the services that it checks.
+type Check struct {
+	Name     string
+	Probes   []Probe
+	Timeout  time.Duration
+	CacheFor time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the outcome of every check of a probe. It passes only if all of
+// them pass.
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+func (r Report) Passed() bool {
+	return r.Status == StatusPass
+}
+
+// Registry holds the checks of the components of the server, which register
+// them as they are built.
+type Registry struct {
+	mu     sync.RWMutex
+	checks []*entry
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+type entry struct {
+	Check
+
+	mu   sync.Mutex
+	last *Result
+}
+
+func NewRegistry() *Registry {
+	return &Registry{now: time.Now}
+}
+
+// Register adds a check. Checks are reported in order of name.
+func (r *Registry) Register(c Check) {
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, &entry{Check: c})
+	sort.SliceStable(r.checks, func(i, j int) bool {
+		return r.checks[i].Name < r.checks[j].Name
+	})
+}
+
+// Run runs the checks of the probe concurrently, or reuses their cached
+// results.
+func (r *Registry) Run(ctx context.Context, probe Probe) Report {
+	r.mu.RLock()
+	var entries []*entry
+	for _, e := range r.checks {
+		if e.answers(probe) {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusPass, Checks: make([]Result, len(entries))}
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusPass {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.last != nil && r.now().Sub(e.last.CheckedAt) < e.CacheFor {
+		return *e.last
+	}
+
+	start := r.now()
+	err := runWithTimeout(ctx, e.Timeout, e.Run)
+	res := Result{
+		Name:      e.Name,
+		Status:    StatusPass,
+		Duration:  r.now().Sub(start).Round(time.Microsecond).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		res.Status, res.Error = StatusFail, err.Error()
+	}
+	e.last = &res
+	return res
+}
+
+// runWithTimeout gives up on fn once timeout has passed, even if fn ignores
+// its context.
+func runWithTimeout(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+func (e *entry) answers(probe Probe) bool {
+	for _, p := range e.Probes {
+		if p == probe {
+			return true
+		}
+	}
+	return false
+}
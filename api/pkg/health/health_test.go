@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry()
+
+	var dbErr error
+	r.Register(Check{Name: "database", Probes: []Probe{Ready}, Run: func(context.Context) error { return dbErr }})
+	r.Register(Check{Name: "worker", Probes: []Probe{Live}, Run: func(context.Context) error { return nil }})
+
+	report := r.Run(ctx, Ready)
+	assert.True(t, report.Passed())
+	require.Len(t, report.Checks, 1, "Only the checks of the probe are run")
+	assert.Equal(t, "database", report.Checks[0].Name)
+
+	dbErr = errors.New("connection refused")
+	report = r.Run(ctx, Ready)
+	assert.False(t, report.Passed())
+	assert.Equal(t, StatusFail, report.Checks[0].Status)
+	assert.Equal(t, "connection refused", report.Checks[0].Error)
+
+	assert.True(t, r.Run(ctx, Live).Passed())
+	assert.True(t, r.Run(ctx, Startup).Passed(), "A probe without checks passes")
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	r := NewRegistry()
+	block := make(chan struct{})
+	defer close(block)
+	r.Register(Check{
+		Name:    "stuck",
+		Probes:  []Probe{Ready},
+		Timeout: 10 * time.Millisecond,
+		Run: func(context.Context) error {
+			<-block
+			return nil
+		},
+	})
+
+	report := r.Run(context.Background(), Ready)
+	assert.False(t, report.Passed(), "A check that ignores its context still times out")
+	assert.Contains(t, report.Checks[0].Error, "timed out")
+}
+
+func TestRegistryCache(t *testing.T) {
+	now := time.Now()
+	r := NewRegistry()
+	r.now = func() time.Time { return now }
+
+	runs := 0
+	r.Register(Check{
+		Name:     "database",
+		Probes:   []Probe{Ready},
+		CacheFor: time.Second,
+		Run: func(context.Context) error {
+			runs++
+			return nil
+		},
+	})
+
+	r.Run(context.Background(), Ready)
+	r.Run(context.Background(), Ready)
+	assert.Equal(t, 1, runs, "The result is reused while it is fresh")
+
+	now = now.Add(time.Second)
+	r.Run(context.Background(), Ready)
+	assert.Equal(t, 2, runs)
+}
@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Bundle holds the messages of every supported locale, and negotiates which
+// one to use from an `Accept-Language` header.
+type Bundle struct {
+	fallback language.Tag
+	tags     []language.Tag
+	messages map[language.Tag]map[string]string
+	matcher  language.Matcher
+}
+
+// LoadBundle reads every `<locale>.json` file in dir, such as `de.json` or
+// `pt-BR.json`. Each file is a flat object of message keys to messages.
+// Messages missing from a locale fall back to its parent locale (`pt` for
+// `pt-BR`), and then to the fallback locale, which must exist.
+func LoadBundle(fsys fs.FS, dir string, fallback language.Tag) (*Bundle, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		fallback: fallback,
+		tags:     []language.Tag{fallback},
+		messages: make(map[language.Tag]map[string]string),
+	}
+
+	for _, file := range files {
+		tag, err := language.Parse(strings.TrimSuffix(path.Base(file), ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		b.messages[tag] = messages
+		if tag != fallback {
+			b.tags = append(b.tags, tag)
+		}
+	}
+
+	if _, ok := b.messages[fallback]; !ok {
+		return nil, fmt.Errorf("no messages for the fallback locale %s in %s", fallback, dir)
+	}
+
+	// The first tag is used when nothing matches.
+	b.matcher = language.NewMatcher(b.tags)
+	return b, nil
+}
+
+// Match returns the supported locale that best satisfies an `Accept-Language`
+// header, or the fallback locale if none does.
+func (b *Bundle) Match(acceptLanguage string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return b.fallback
+	}
+
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence == language.No {
+		return b.fallback
+	}
+	return b.tags[index]
+}
+
+// Message returns the message for key in the given locale, with each
+// `{name}` placeholder replaced by params[name]. It reports false if no locale
+// in the fallback chain has the key.
+func (b *Bundle) Message(tag language.Tag, key string, params map[string]string) (string, bool) {
+	for _, t := range b.chain(tag) {
+		if msg, ok := b.messages[t][key]; ok {
+			for name, value := range params {
+				msg = strings.ReplaceAll(msg, "{"+name+"}", value)
+			}
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// chain returns tag, its parents, and finally the fallback locale.
+func (b *Bundle) chain(tag language.Tag) []language.Tag {
+	var chain []language.Tag
+	for t := tag; t != language.Und; t = t.Parent() {
+		chain = append(chain, t)
+	}
+	return append(chain, b.fallback)
+}
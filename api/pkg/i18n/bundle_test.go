@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestBundle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en.json":    {Data: []byte(`{"greeting": "Hello, {name}!", "farewell": "Goodbye"}`)},
+		"locales/de.json":    {Data: []byte(`{"greeting": "Hallo, {name}!"}`)},
+		"locales/pt.json":    {Data: []byte(`{"greeting": "Olá, {name}!", "farewell": "Adeus"}`)},
+		"locales/pt-BR.json": {Data: []byte(`{"greeting": "Oi, {name}!"}`)},
+	}
+
+	b, err := LoadBundle(fsys, "locales", language.English)
+	assert.NoError(t, err)
+
+	tests := [...]struct {
+		name           string
+		acceptLanguage string
+		key            string
+		expected       string
+	}{
+		{"Default", "", "greeting", "Hello, Ana!"},
+		{"Unsupported", "ja", "greeting", "Hello, Ana!"},
+		{"Exact", "pt-BR", "greeting", "Oi, Ana!"},
+		{"Weighted", "ja, de;q=0.9, en;q=0.8", "greeting", "Hallo, Ana!"},
+		{"FallsBackToParent", "pt-BR", "farewell", "Adeus"},
+		{"FallsBackToDefault", "pt-BR", "unknown", ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			msg, _ := b.Message(b.Match(tt.acceptLanguage), tt.key, map[string]string{"name": "Ana"})
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestLoadBundleRequiresFallback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/de.json": {Data: []byte(`{}`)},
+	}
+
+	_, err := LoadBundle(fsys, "locales", language.English)
+	assert.Error(t, err)
+}
@@ -1,16 +1,27 @@
 package logger
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 // DefaultLogger is a simple logger that prints all messages to STDOUT with a
 // prefix of either of the following using `fmt`: TRACE, DEBUG, INFO, WARN,
 // ERROR, FATAL.
 type DefaultLogger struct {
 	Logger
+	out io.Writer
 }
 
 func NewDefaultLogger() *DefaultLogger {
-	return &DefaultLogger{}
+	return NewDefaultLoggerTo(os.Stdout)
+}
+
+// NewDefaultLoggerTo prints to w instead of STDOUT, e.g. to STDERR for
+// commands whose output is data.
+func NewDefaultLoggerTo(w io.Writer) *DefaultLogger {
+	return &DefaultLogger{out: w}
 }
 
 // unpack returns a string with spaces between each word.
@@ -32,25 +43,25 @@ func (l *DefaultLogger) Config() {
 }
 
 func (l *DefaultLogger) Trace(s ...any) {
-	fmt.Println("TRACE:", unpack(s...))
+	fmt.Fprintln(l.out, "TRACE:", unpack(s...))
 }
 
 func (l *DefaultLogger) Debug(s ...any) {
-	fmt.Println("DEBUG:", unpack(s...))
+	fmt.Fprintln(l.out, "DEBUG:", unpack(s...))
 }
 
 func (l *DefaultLogger) Info(s ...any) {
-	fmt.Println("INFO:", unpack(s...))
+	fmt.Fprintln(l.out, "INFO:", unpack(s...))
 }
 
 func (l *DefaultLogger) Warn(s ...any) {
-	fmt.Println("WARN:", unpack(s...))
+	fmt.Fprintln(l.out, "WARN:", unpack(s...))
 }
 
 func (l *DefaultLogger) Error(s ...any) {
-	fmt.Println("ERROR:", unpack(s...))
+	fmt.Fprintln(l.out, "ERROR:", unpack(s...))
 }
 
 func (l *DefaultLogger) Fatal(s ...any) {
-	fmt.Println("FATAL:", unpack(s...))
+	fmt.Fprintln(l.out, "FATAL:", unpack(s...))
 }
@@ -0,0 +1,43 @@
+// Package metrics holds the Prometheus registry that the components of the
+// server register their metrics in.
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Namespace prefixes the names of the metrics of the blogger app.
+const Namespace = "blogger"
+
+// NewRegistry returns a registry with the metrics of the Go runtime and of the
+// process.
+func NewRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return r
+}
+
+// Register registers c, and returns it. If an identical collector is already
+// registered, e.g. by another instance of the same service, that one is
+// returned instead, so that they share it. It panics on any other conflict,
+// like MustRegister, as that is a programming error.
+func Register[C prometheus.Collector](r prometheus.Registerer, c C) C {
+	err := r.Register(c)
+	if err == nil {
+		return c
+	}
+
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(C); ok {
+			return existing
+		}
+	}
+	panic(err)
+}
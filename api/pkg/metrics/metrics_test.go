@@ -0,0 +1,21 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	r := NewRegistry()
+	opts := prometheus.CounterOpts{Namespace: Namespace, Name: "writes_total", Help: "Writes."}
+
+	first := Register(r, prometheus.NewCounter(opts))
+	second := Register(r, prometheus.NewCounter(opts))
+	assert.Same(t, first, second, "Identical collectors are shared")
+
+	assert.Panics(t, func() {
+		Register(r, prometheus.NewGauge(prometheus.GaugeOpts(opts)))
+	}, "Conflicting collectors are rejected")
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Backoff computes the delays between attempts, which double from Initial up
+// to Max. Each delay is jittered between half and all of its value, so that
+// clients that failed together do not retry together.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+var DefaultBackoff = Backoff{
+	Initial: 500 * time.Millisecond,
+	Max:     10 * time.Second,
+}
+
+// Delay returns the delay after the given failed attempt, counting from 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := b.Initial
+	for i := 1; i < attempt && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)))
+}
+
+// Do calls fn until it succeeds or ctx is done, waiting between attempts as
+// set by b. onRetry, if set, is called before each wait. The error of the
+// last attempt is returned.
+func Do(ctx context.Context, b Backoff, fn func() error, onRetry func(err error, delay time.Duration)) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		delay := b.Delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		if onRetry != nil {
+			onRetry(err, delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+	for attempt, max := range []time.Duration{0, 100, 200, 400, 800, 1000, 1000} {
+		if attempt == 0 {
+			continue
+		}
+		d := b.Delay(attempt)
+		assert.GreaterOrEqual(t, d, max*time.Millisecond/2, "attempt %d", attempt)
+		assert.Less(t, d, max*time.Millisecond, "attempt %d", attempt)
+	}
+}
+
+func TestDo(t *testing.T) {
+	b := Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+
+	attempts := 0
+	err := Do(context.Background(), b, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	failure := errors.New("down")
+	err = Do(ctx, b, func() error { return failure }, nil)
+	assert.ErrorIs(t, err, failure, "The last error is returned once the deadline passes")
+}
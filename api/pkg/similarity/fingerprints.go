@@ -0,0 +1,48 @@
+package similarity
+
+import "sync"
+
+// Fingerprints holds the SimHash fingerprints of a set of documents, so that a
+// new text can be compared against them without reading them all back. It is
+// safe for concurrent use.
+type Fingerprints struct {
+	mu sync.RWMutex
+	m  map[uint]uint64
+}
+
+func NewFingerprints() *Fingerprints {
+	return &Fingerprints{m: make(map[uint]uint64)}
+}
+
+// Set adds the fingerprint of a document, replacing any previous one.
+func (f *Fingerprints) Set(id uint, fingerprint uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.m[id] = fingerprint
+}
+
+// Remove forgets the fingerprint of a document, if it has one.
+func (f *Fingerprints) Remove(id uint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.m, id)
+}
+
+// Closest returns the document whose fingerprint is the most similar to
+// fingerprint, other than except, and their Similarity. Ties go to the lowest
+// ID. ok is false when there is no other document.
+func (f *Fingerprints) Closest(fingerprint uint64, except uint) (id uint, score float64, ok bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for other, o := range f.m {
+		if other == except {
+			continue
+		}
+		s := Similarity(fingerprint, o)
+		if !ok || s > score || (s == score && other < id) {
+			id, score, ok = other, s, true
+		}
+	}
+	return id, score, ok
+}
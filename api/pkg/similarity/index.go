@@ -0,0 +1,244 @@
+package similarity
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	utils "example.com/m/v2/pkg/utils"
+)
+
+// DefaultTagBoost is added to a match's score, scaled by the Jaccard
+// similarity of the two documents' tags.
+const DefaultTagBoost = 0.25
+
+// Document is a unit of text to be indexed. Tags are optional.
+type Document struct {
+	ID   uint
+	Text string
+	Tags []string
+}
+
+// Match is a document related to the one that was queried, and how closely.
+type Match struct {
+	ID    uint
+	Score float64
+}
+
+type entry struct {
+	terms map[string]int
+	tags  map[string]struct{}
+	norm  float64
+}
+
+// Index ranks documents by the cosine similarity of their TF-IDF vectors. Term
+// frequencies are maintained incrementally as documents are added and
+// removed, so a query only touches the documents that share a term with it.
+// It is safe for concurrent use.
+type Index struct {
+	TagBoost float64
+
+	mu       sync.RWMutex
+	docs     map[uint]*entry
+	postings map[string]map[uint]int // term -> document -> term frequency
+
+	// Document norms depend on the inverse document frequency of every term,
+	// which changes with every write. They are recomputed lazily on the next
+	// query instead.
+	normsValid bool
+}
+
+func NewIndex() *Index {
+	return &Index{
+		TagBoost: DefaultTagBoost,
+		docs:     make(map[uint]*entry),
+		postings: make(map[string]map[uint]int),
+	}
+}
+
+// Upsert adds the document to the index, replacing any previous version.
+func (i *Index) Upsert(d Document) {
+	e := &entry{
+		terms: make(map[string]int),
+		tags:  make(map[string]struct{}),
+	}
+	for _, token := range utils.Tokenize(d.Text) {
+		e.terms[token]++
+	}
+	for _, tag := range d.Tags {
+		e.tags[tag] = struct{}{}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(d.ID)
+	i.docs[d.ID] = e
+	for term, tf := range e.terms {
+		if i.postings[term] == nil {
+			i.postings[term] = make(map[uint]int)
+		}
+		i.postings[term][d.ID] = tf
+	}
+	i.normsValid = false
+}
+
+func (i *Index) Remove(id uint) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+}
+
+func (i *Index) remove(id uint) {
+	e, ok := i.docs[id]
+	if !ok {
+		return
+	}
+
+	for term := range e.terms {
+		delete(i.postings[term], id)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.docs, id)
+	i.normsValid = false
+}
+
+// Has reports whether a document with the given id has been indexed.
+func (i *Index) Has(id uint) bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	_, ok := i.docs[id]
+	return ok
+}
+
+// Len returns the number of indexed documents.
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return len(i.docs)
+}
+
+// Related returns up to limit documents most similar to the document with the
+// given id, best match first. Documents with nothing in common are omitted.
+func (i *Index) Related(id uint, limit int) []Match {
+	// Writes wait for the read lock, so the norms stay valid while it is held.
+	i.mu.RLock()
+	if i.normsValid {
+		defer i.mu.RUnlock()
+		return i.related(id, limit)
+	}
+	i.mu.RUnlock()
+
+	// Otherwise, score under the same write lock as the norms are computed
+	// under, so that no write invalidates them in between.
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !i.normsValid {
+		i.computeNorms()
+	}
+	return i.related(id, limit)
+}
+
+// related must be called with a lock held, and valid norms.
+func (i *Index) related(id uint, limit int) []Match {
+	q, ok := i.docs[id]
+	if !ok || limit <= 0 {
+		return []Match{}
+	}
+
+	scores := make(map[uint]float64)
+	if q.norm > 0 {
+		for term, qtf := range q.terms {
+			postings := i.postings[term]
+			idf := i.idf(len(postings))
+			wq := weight(qtf, idf)
+			for other, tf := range postings {
+				if other != id {
+					scores[other] += wq * weight(tf, idf)
+				}
+			}
+		}
+		for other, dot := range scores {
+			if norm := i.docs[other].norm; norm > 0 {
+				scores[other] = dot / (q.norm * norm)
+			}
+		}
+	}
+
+	if len(q.tags) > 0 && i.TagBoost > 0 {
+		for other, e := range i.docs {
+			if other == id {
+				continue
+			}
+			if j := jaccard(q.tags, e.tags); j > 0 {
+				scores[other] += i.TagBoost * j
+			}
+		}
+	}
+
+	matches := make([]Match, 0, len(scores))
+	for other, score := range scores {
+		if score > 0 {
+			matches = append(matches, Match{ID: other, Score: score})
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].ID < matches[b].ID
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// computeNorms must be called with the write lock held.
+func (i *Index) computeNorms() {
+	for _, e := range i.docs {
+		var sum float64
+		for term, tf := range e.terms {
+			w := weight(tf, i.idf(len(i.postings[term])))
+			sum += w * w
+		}
+		e.norm = math.Sqrt(sum)
+	}
+	i.normsValid = true
+}
+
+// idf is smoothed so that a term present in every document still carries a
+// small weight, rather than none at all.
+func (i *Index) idf(df int) float64 {
+	if df == 0 {
+		return 0
+	}
+	return math.Log(1 + float64(len(i.docs))/float64(df))
+}
+
+// weight uses a sub-linear term frequency, so that a word repeated many times
+// does not dominate the vector.
+func weight(tf int, idf float64) float64 {
+	return (1 + math.Log(float64(tf))) * idf
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	var shared int
+	for tag := range a {
+		if _, ok := b[tag]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,76 @@
+package similarity
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelated(t *testing.T) {
+	i := NewIndex()
+	i.Upsert(Document{ID: 1, Text: "Docker containers and Kubernetes pods"})
+	i.Upsert(Document{ID: 2, Text: "Running Kubernetes pods in production"})
+	i.Upsert(Document{ID: 3, Text: "Docker containers for local development"})
+	i.Upsert(Document{ID: 4, Text: "Baking sourdough bread at home"})
+
+	matches := i.Related(1, 5)
+
+	assert.Len(t, matches, 2, "Unrelated documents are omitted")
+	assert.ElementsMatch(t, []uint{2, 3}, []uint{matches[0].ID, matches[1].ID})
+	assert.Empty(t, i.Related(4, 5))
+	assert.Len(t, i.Related(1, 1), 1, "The limit is respected")
+	assert.Empty(t, i.Related(42, 5), "Unknown documents have no matches")
+}
+
+func TestRelatedIsIncremental(t *testing.T) {
+	i := NewIndex()
+	i.Upsert(Document{ID: 1, Text: "golang generics"})
+	i.Upsert(Document{ID: 2, Text: "golang generics tutorial"})
+	assert.Equal(t, uint(2), i.Related(1, 5)[0].ID)
+
+	i.Upsert(Document{ID: 2, Text: "baking bread"})
+	assert.Empty(t, i.Related(1, 5), "Updates replace the previous version")
+
+	i.Upsert(Document{ID: 3, Text: "more golang"})
+	i.Remove(3)
+	assert.Empty(t, i.Related(1, 5), "Removed documents are no longer matched")
+	assert.Equal(t, 2, i.Len())
+}
+
+func TestRelatedTagBoost(t *testing.T) {
+	i := NewIndex()
+	i.Upsert(Document{ID: 1, Text: "intro to containers", Tags: []string{"docker"}})
+	i.Upsert(Document{ID: 2, Text: "intro to pods", Tags: []string{"kubernetes"}})
+	i.Upsert(Document{ID: 3, Text: "intro to images", Tags: []string{"docker"}})
+
+	matches := i.Related(1, 5)
+
+	assert.Equal(t, uint(3), matches[0].ID, "Shared tags rank higher")
+	assert.Greater(t, matches[0].Score, matches[1].Score)
+}
+
+// TestRelatedConcurrentWrites scores while documents are written. Scores must
+// stay cosines: a document whose norm is not computed yet must not be scored.
+func TestRelatedConcurrentWrites(t *testing.T) {
+	i := NewIndex()
+	i.Upsert(Document{ID: 1, Text: "golang generics with type parameters and constraints"})
+
+	var wg sync.WaitGroup
+	for n := uint(2); n < 202; n++ {
+		wg.Add(2)
+		go func(n uint) {
+			defer wg.Done()
+			i.Upsert(Document{ID: n, Text: "a tutorial on golang generics with type parameters and constraints"})
+		}(n)
+		go func() {
+			defer wg.Done()
+			for _, m := range i.Related(1, 5) {
+				assert.LessOrEqual(t, m.Score, 1+1e-9, "Scores are cosines")
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, i.Related(1, 500), 200)
+}
@@ -0,0 +1,60 @@
+package similarity
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+
+	utils "example.com/m/v2/pkg/utils"
+)
+
+// ShingleSize is the number of consecutive words hashed together by SimHash.
+// Word order matters within a shingle, so reordered sentences still differ.
+const ShingleSize = 3
+
+// SimHash returns a 64-bit fingerprint of text. Texts that share most of their
+// shingles have fingerprints that differ in only a few bits, so that their
+// similarity can be estimated without comparing the texts themselves.
+//
+// A text without words has no shingles to hash. It gets no fingerprint, and ok
+// is false, since all such texts would otherwise be identical.
+func SimHash(text string) (fingerprint uint64, ok bool) {
+	tokens := utils.Tokenize(text)
+	if len(tokens) == 0 {
+		return 0, false
+	}
+
+	var counts [64]int
+	add := func(shingle string) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				counts[bit]++
+			} else {
+				counts[bit]--
+			}
+		}
+	}
+
+	if len(tokens) < ShingleSize {
+		add(strings.Join(tokens, " "))
+	}
+	for i := 0; i+ShingleSize <= len(tokens); i++ {
+		add(strings.Join(tokens[i:i+ShingleSize], " "))
+	}
+
+	for bit, count := range counts {
+		if count > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint, true
+}
+
+// Similarity estimates how alike the texts behind two SimHash fingerprints
+// are, from 0 (unrelated) to 1 (identical shingles).
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
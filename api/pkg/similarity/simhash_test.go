@@ -0,0 +1,62 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimHash(t *testing.T) {
+	original := `Docker Compose lets you define a multi-container application in a
+		single file. In this post we will run an API and a PostgreSQL database
+		side by side, wire them together over a private network, and mount
+		the source code into the container so that changes are picked up
+		without rebuilding the image every time.`
+	repost := `Docker Compose lets you define a multi-container application in a
+		single file! In this post we will run an API and a PostgreSQL database
+		side by side, wire them together over a private network, and mount
+		the source code into the container so that changes are picked up
+		without rebuilding the image each time.`
+	unrelated := `Sourdough needs a lively starter, a long cold proof, and a very
+		hot oven. Score the loaf just before baking so that it can rise
+		evenly, and let it cool completely before slicing into it.`
+
+	hash := func(text string) uint64 {
+		fingerprint, ok := SimHash(text)
+		assert.True(t, ok)
+		return fingerprint
+	}
+
+	assert.Equal(t, hash(original), hash(original), "Fingerprints are deterministic")
+	assert.Greater(t, Similarity(hash(original), hash(repost)), 0.9, "Near-duplicates are similar")
+	assert.Less(t, Similarity(hash(original), hash(unrelated)), 0.9, "Unrelated texts are not similar")
+	_, ok := SimHash("  !!  ")
+	assert.False(t, ok, "Texts without words have no fingerprint")
+}
+
+func TestFingerprints(t *testing.T) {
+	f := NewFingerprints()
+	_, _, ok := f.Closest(0, 0)
+	assert.False(t, ok, "An empty set has no closest fingerprint")
+
+	f.Set(1, 0b1111)
+	f.Set(2, 0b0111)
+	f.Set(3, 0b0011)
+
+	id, score, ok := f.Closest(0b1111, 0)
+	assert.True(t, ok)
+	assert.Equal(t, uint(1), id)
+	assert.Equal(t, 1.0, score)
+
+	id, _, _ = f.Closest(0b1111, 1)
+	assert.Equal(t, uint(2), id, "The excepted document is skipped")
+
+	f.Set(4, 0b1011)
+	id, _, _ = f.Closest(0b1111, 1)
+	assert.Equal(t, uint(2), id, "Ties go to the lowest ID")
+
+	f.Remove(2)
+	f.Remove(4)
+	id, _, _ = f.Closest(0b1111, 1)
+	assert.Equal(t, uint(3), id)
+}
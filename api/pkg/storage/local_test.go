@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func read(t *testing.T, s Storage, key string) string {
+	t.Helper()
+	r, err := s.Get(key)
+	require.NoError(t, err)
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestLocalStorage(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "attachments")
+	s := NewLocalStorage(root)
+
+	exists, err := s.Exists("checksum")
+	assert.NoError(t, err)
+	assert.False(t, exists, "Nothing is stored before the first write")
+
+	require.NoError(t, s.Put("checksum", strings.NewReader("first")))
+	exists, err = s.Exists("checksum")
+	assert.NoError(t, err)
+	assert.True(t, exists, "A stored object is found, so that identical uploads are stored once")
+	assert.Equal(t, "first", read(t, s, "checksum"))
+
+	require.NoError(t, s.Put("checksum", strings.NewReader("second")))
+	assert.Equal(t, "second", read(t, s, "checksum"), "Put overwrites an existing object")
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "No temporary files are left behind")
+
+	require.NoError(t, s.Delete("checksum"))
+	_, err = s.Get("checksum")
+	assert.ErrorIs(t, err, ErrNotExist)
+	assert.ErrorIs(t, s.Delete("checksum"), ErrNotExist)
+}
+
+func TestLocalStorageMissingKey(t *testing.T) {
+	s := NewLocalStorage(t.TempDir())
+
+	_, err := s.Get("missing")
+	assert.ErrorIs(t, err, ErrNotExist)
+	exists, err := s.Exists("missing")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestLocalStorageInvalidKeys(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(filepath.Join(dir, "attachments"))
+	outside := filepath.Join(dir, "outside")
+
+	for _, key := range []string{"", ".", "..", "../outside", "a/b", `..\outside`, outside} {
+		assert.Error(t, s.Put(key, strings.NewReader("escaped")), "Put(%q)", key)
+		_, err := s.Get(key)
+		assert.Error(t, err, "Get(%q)", key)
+		assert.NotErrorIs(t, err, ErrNotExist, "Get(%q)", key)
+		_, err = s.Exists(key)
+		assert.Error(t, err, "Exists(%q)", key)
+		assert.Error(t, s.Delete(key), "Delete(%q)", key)
+	}
+
+	_, err := os.Stat(outside)
+	assert.ErrorIs(t, err, os.ErrNotExist, "Nothing is written outside of the root")
+}
@@ -0,0 +1,18 @@
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotExist is returned when no object is stored under the requested key.
+var ErrNotExist = errors.New("object does not exist")
+
+// Storage persists opaque binary objects (such as uploaded images) under a
+// key. Implementations must be safe for concurrent use.
+type Storage interface {
+	Put(key string, r io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Exists(key string) (bool, error)
+	Delete(key string) error
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"regexp"
+	"strings"
 )
 
 // Returns a string with all non-word characters removed.
@@ -9,3 +10,16 @@ func ReplaceSymbols(s string) string {
 	m := regexp.MustCompile("[^a-zA-Z0-9]")
 	return m.ReplaceAllString(s, "")
 }
+
+// Tokenize splits s into lower-cased words with all symbols removed. Words
+// that consist only of symbols are dropped.
+func Tokenize(s string) []string {
+	fields := strings.Fields(s)
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if token := strings.ToLower(ReplaceSymbols(field)); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
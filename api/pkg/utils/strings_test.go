@@ -23,3 +23,9 @@ func TestReplaceMultipleSpaces(t *testing.T) {
 
 	assert.Equal(t, "helloworld", ReplaceSymbols(s), "Multiple spaces in sequence are replaced")
 }
+
+func TestTokenize(t *testing.T) {
+	s := "Hello, World!  hello -- world's\tEND."
+
+	assert.Equal(t, []string{"hello", "world", "hello", "worlds", "end"}, Tokenize(s), "Words are cleaned, lower-cased and empty words dropped")
+}
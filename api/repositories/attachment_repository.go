@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	"gorm.io/gorm"
+)
+
+type AttachmentCreator interface {
+	Create(ctx context.Context, m *models.Attachment) (*models.Attachment, error)
+}
+
+type SingleAttachmentGetter interface {
+	GetByID(ctx context.Context, id string) (*models.Attachment, error)
+}
+
+// AttachmentChecksumGetter finds an attachment of a blog by the checksum of
+// its content, which is how duplicate uploads are detected.
+type AttachmentChecksumGetter interface {
+	GetByChecksum(ctx context.Context, blogID uint, checksum string) (*models.Attachment, error)
+}
+
+type AttachmentRepository interface {
+	AttachmentCreator
+	SingleAttachmentGetter
+	AttachmentChecksumGetter
+}
+
+type PostgreSQLAttachmentRepository struct {
+	ioc *ioc.IOC
+	db  *gorm.DB
+}
+
+func NewPostgreSQLAttachmentRepository(c *ioc.IOC, db *gorm.DB) *PostgreSQLAttachmentRepository {
+	return &PostgreSQLAttachmentRepository{
+		ioc: c,
+		db:  db,
+	}
+}
+
+func (r *PostgreSQLAttachmentRepository) Create(ctx context.Context, m *models.Attachment) (*models.Attachment, error) {
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *PostgreSQLAttachmentRepository) GetByID(ctx context.Context, id string) (*models.Attachment, error) {
+	n, ok := parseID(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var m models.Attachment
+	if err := r.db.WithContext(ctx).First(&m, n).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *PostgreSQLAttachmentRepository) GetByChecksum(ctx context.Context, blogID uint, checksum string) (*models.Attachment, error) {
+	var m models.Attachment
+	if err := r.db.WithContext(ctx).Where("blog_id = ? AND checksum = ?", blogID, checksum).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// MemoryAttachmentRepository is the in-memory counterpart of
+// PostgreSQLAttachmentRepository. It does not check that the blog exists.
+type MemoryAttachmentRepository struct {
+	*MemoryRepository[models.Attachment]
+}
+
+func NewMemoryAttachmentRepository() *MemoryAttachmentRepository {
+	return &MemoryAttachmentRepository{
+		MemoryRepository: NewMemoryRepository("attachments", UniqueConstraint[models.Attachment]{
+			Column: "blog_id_checksum",
+			Key: func(m *models.Attachment) string {
+				return fmt.Sprintf("%d/%s", m.BlogID, m.Checksum)
+			},
+		}),
+	}
+}
+
+func (r *MemoryAttachmentRepository) GetByChecksum(ctx context.Context, blogID uint, checksum string) (*models.Attachment, error) {
+	attachments, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range attachments {
+		if m.BlogID == blogID && m.Checksum == checksum {
+			return m, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+
 	"example.com/m/v2/ioc"
 	models "example.com/m/v2/models"
 	"gorm.io/gorm"
@@ -12,83 +14,102 @@ import (
 // the db instance passed into the Service's methods must conform to 100%
 // of the defined interface, when in reality, we only care about a small part
 // of that interface at one given time.
-type BlogCreator interface {
-	Create(m *models.Blog) (*models.Blog, error)
-}
+type BlogCreator = Creator[models.Blog]
 
-type MultiBlogGetter interface {
-	GetAll() ([]*models.Blog, error)
-}
+type MultiBlogGetter = Lister[models.Blog]
+
+type SingleBlogGetter = Getter[models.Blog]
+
+type BlogsByIDGetter = ByIDsGetter[models.Blog]
 
-type SingleBlogGetter interface {
-	GetByID(id string) (*models.Blog, error)
+// RelatedBlogGetter is used to look up a blog, and then the blogs related to it.
+type RelatedBlogGetter interface {
+	SingleBlogGetter
+	BlogsByIDGetter
 }
 
-type BlogUpdater interface {
-	Update(id uint, m *models.Blog) (*models.Blog, error)
+type BlogUpdater = Updater[models.Blog]
+
+// BlogFingerprintSetter stores the fingerprint of a blog, without changing
+// the rest of it. It backfills the blogs written before they had one.
+type BlogFingerprintSetter interface {
+	SetFingerprint(ctx context.Context, id uint, fingerprint int64) error
 }
 
-type BlogDeleter interface {
-	Delete(id string) error
+// BlogIndexer lists the blogs to index, and backfills their fingerprints.
+type BlogIndexer interface {
+	MultiBlogGetter
+	BlogFingerprintSetter
 }
 
+type BlogDeleter = Deleter[models.Blog]
+
 type BlogRepository interface {
-	BlogCreator
-	MultiBlogGetter
-	SingleBlogGetter
-	BlogUpdater
-	BlogDeleter
+	Repository[models.Blog]
+	BlogFingerprintSetter
+}
+
+// BlogSessioner starts a session for a request, which reads its own writes.
+// See PostgreSQLRepository.Session.
+type BlogSessioner interface {
+	Session(readPrimary bool) BlogRepository
 }
 
+// PostgreSQLBlogRepository inherits the CRUD methods from
+// PostgreSQLRepository, and adds the queries that only blogs need.
 type PostgreSQLBlogRepository struct {
-	ioc *ioc.IOC
-	db  *gorm.DB
+	*PostgreSQLRepository[models.Blog]
 }
 
 // TODO: so the article says returning BlogRepository here would be bad
 // b/c it'd force the tester to have to mock everything!
 func NewPostgreSQLBlogRepository(c *ioc.IOC, db *gorm.DB) *PostgreSQLBlogRepository {
 	return &PostgreSQLBlogRepository{
-		ioc: c,
-		db:  db,
+		PostgreSQLRepository: NewPostgreSQLRepository[models.Blog](c, db),
 	}
 }
 
-func (r *PostgreSQLBlogRepository) Create(m *models.Blog) (*models.Blog, error) {
-	if err := r.db.Save(&m).Error; err != nil {
-		return nil, err
+func (r *PostgreSQLBlogRepository) WithReplicas(replicas ReadRouter) *PostgreSQLBlogRepository {
+	return &PostgreSQLBlogRepository{
+		PostgreSQLRepository: r.PostgreSQLRepository.WithReplicas(replicas),
 	}
-	return m, nil
 }
 
-func (r *PostgreSQLBlogRepository) GetByID(id string) (*models.Blog, error) {
-	var m models.Blog
-	if err := r.db.First(&m, id).Error; err != nil {
-		return nil, err
+func (r *PostgreSQLBlogRepository) Session(readPrimary bool) BlogRepository {
+	return &PostgreSQLBlogRepository{
+		PostgreSQLRepository: r.PostgreSQLRepository.Session(readPrimary),
 	}
-	return &m, nil
 }
 
-func (r *PostgreSQLBlogRepository) GetAll() ([]*models.Blog, error) {
-	var m []*models.Blog
-	if err := r.db.Find(&m).Error; err != nil {
-		return nil, err
-	}
-	return m, nil
+// SetFingerprint leaves `updated_at` alone, since the content of the blog
+// does not change.
+func (r *PostgreSQLBlogRepository) SetFingerprint(ctx context.Context, id uint, fingerprint int64) error {
+	return r.writer(ctx).Model(&models.Blog{}).Where("id = ?", id).UpdateColumn("fingerprint", fingerprint).Error
+}
+
+// MemoryBlogRepository is the in-memory counterpart of
+// PostgreSQLBlogRepository, selected with `REPOSITORY_BACKEND=memory`.
+type MemoryBlogRepository struct {
+	*MemoryRepository[models.Blog]
 }
 
-func (r *PostgreSQLBlogRepository) Update(id uint, m *models.Blog) (*models.Blog, error) {
-	// TODO: make immutable by fetching, merging, and persisting
-	m.ID = id
-	if err := r.db.Save(&m).Error; err != nil {
-		return nil, err
+func NewMemoryBlogRepository() *MemoryBlogRepository {
+	return &MemoryBlogRepository{
+		MemoryRepository: NewMemoryRepository("blogs", UniqueConstraint[models.Blog]{
+			Column: "title",
+			Key:    func(m *models.Blog) string { return m.Title },
+		}),
 	}
-	return m, nil
 }
 
-func (r *PostgreSQLBlogRepository) Delete(id string) error {
-	if err := r.db.Delete(&models.Blog{}, id).Error; err != nil {
-		return err
+func (r *MemoryBlogRepository) SetFingerprint(_ context.Context, id uint, fingerprint int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	blog, ok := r.find(id)
+	if !ok {
+		return gorm.ErrRecordNotFound
 	}
+	blog.Fingerprint = &fingerprint
 	return nil
 }
@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"example.com/m/v2/ioc"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// DefaultCacheChannel is the channel that blog invalidations are sent on.
+const DefaultCacheChannel = "blog_cache"
+
+// PostgreSQLCacheInvalidator sends invalidations between replicas with
+// PostgreSQL's `LISTEN`/`NOTIFY`. The payload of a notification is
+// `<origin>:<id>`, where origin identifies the replica that sent it, so that
+// replicas ignore their own notifications.
+type PostgreSQLCacheInvalidator struct {
+	ioc     *ioc.IOC
+	db      *gorm.DB
+	dsn     string
+	channel string
+	origin  string
+
+	mu       sync.RWMutex
+	handlers []func(id uint)
+}
+
+// NewPostgreSQLCacheInvalidator notifies through db, and listens on a
+// dedicated connection to dsn, since gorm pools its connections. Call Listen
+// to start receiving notifications.
+func NewPostgreSQLCacheInvalidator(c *ioc.IOC, db *gorm.DB, dsn string, channel string) *PostgreSQLCacheInvalidator {
+	origin := make([]byte, 8)
+	_, _ = rand.Read(origin)
+
+	return &PostgreSQLCacheInvalidator{
+		ioc:     c,
+		db:      db,
+		dsn:     dsn,
+		channel: channel,
+		origin:  hex.EncodeToString(origin),
+	}
+}
+
+func (i *PostgreSQLCacheInvalidator) Publish(ctx context.Context, id uint) error {
+	return i.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", i.channel, fmt.Sprintf("%s:%d", i.origin, id)).Error
+}
+
+func (i *PostgreSQLCacheInvalidator) Subscribe(fn func(id uint)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.handlers = append(i.handlers, fn)
+}
+
+// Listen receives notifications until ctx is done, reconnecting whenever the
+// connection is lost. Since notifications are lost along with it, subscribers
+// are told to invalidate everything after each reconnection.
+func (i *PostgreSQLCacheInvalidator) Listen(ctx context.Context) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		connected, err := i.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			backoff = time.Second
+		}
+		i.ioc.Logger.Warn("Lost the cache invalidation channel, reconnecting in", backoff, "after:", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+// listen returns when the connection fails, reporting whether it got as far as
+// listening.
+func (i *PostgreSQLCacheInvalidator) listen(ctx context.Context) (bool, error) {
+	conn, err := pgx.Connect(ctx, i.dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{i.channel}.Sanitize()); err != nil {
+		return false, err
+	}
+	i.notify(0)
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		origin, payload, ok := strings.Cut(notification.Payload, ":")
+		if !ok || origin == i.origin {
+			continue
+		}
+		id, err := strconv.ParseUint(payload, 10, 64)
+		if err != nil {
+			continue
+		}
+		i.notify(uint(id))
+	}
+}
+
+func (i *PostgreSQLCacheInvalidator) notify(id uint) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	for _, fn := range i.handlers {
+		fn(id)
+	}
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"example.com/m/v2/config"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	cache "example.com/m/v2/pkg/cache"
+)
+
+// CacheInvalidator tells the caches of every replica that a blog changed. An
+// ID of 0 means that any blog may have changed, e.g. after notifications were
+// missed.
+type CacheInvalidator interface {
+	Publish(ctx context.Context, id uint) error
+	Subscribe(fn func(id uint))
+}
+
+// CacheStatser reports the statistics of each cache held by a repository.
+type CacheStatser interface {
+	Stats() map[string]cache.Stats
+}
+
+// CacheBypasser returns the repository that a cache wraps, to serve requests
+// that must not be answered from the cache.
+type CacheBypasser interface {
+	Bypass() BlogRepository
+}
+
+// CachedBlogRepository caches the blogs read through GetByID and GetAll, and
+// invalidates them when they are written through it. Other reads and every
+// write go to the wrapped repository.
+//
+// A blog read from a replica may lag behind a write to the primary. So nothing
+// is cached for `cache.replica_lag` after an invalidation; a replica that lags
+// further behind may still have its blogs cached until they expire.
+type CachedBlogRepository struct {
+	BlogRepository
+	*blogCache
+
+	// readPrimary sessions read around the cache.
+	readPrimary bool
+}
+
+// blogCache is shared by a CachedBlogRepository and its sessions.
+type blogCache struct {
+	ioc         *ioc.IOC
+	blogs       *cache.LRU[uint, models.Blog]
+	lists       *cache.LRU[string, []models.Blog]
+	invalidator CacheInvalidator
+	replicaLag  time.Duration
+
+	// invalidatedAt is the time of the last invalidation, in Unix nanoseconds.
+	invalidatedAt atomic.Int64
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// allBlogs is the key of GetAll in the lists cache.
+const allBlogs = "all"
+
+// NewCachedBlogRepository wraps r with a cache. invalidator is optional;
+// without it, writes served by other replicas are only seen once the cached
+// blogs expire.
+func NewCachedBlogRepository(c *ioc.IOC, r BlogRepository, config config.Cache, invalidator CacheInvalidator) *CachedBlogRepository {
+	cached := &CachedBlogRepository{
+		BlogRepository: r,
+		blogCache: &blogCache{
+			ioc:         c,
+			blogs:       cache.NewLRU[uint, models.Blog](config.Size, config.TTL),
+			lists:       cache.NewLRU[string, []models.Blog](1, config.TTL),
+			invalidator: invalidator,
+			replicaLag:  config.ReplicaLag,
+			now:         time.Now,
+		},
+	}
+	if invalidator != nil {
+		invalidator.Subscribe(cached.invalidate)
+	}
+	return cached
+}
+
+func (r *CachedBlogRepository) GetByID(ctx context.Context, id string) (*models.Blog, error) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || r.readPrimary {
+		return r.BlogRepository.GetByID(ctx, id)
+	}
+
+	if blog, ok := r.blogs.Get(uint(n)); ok {
+		return &blog, nil
+	}
+
+	// A read that races with a write does not cache what it read, as it may
+	// be stale.
+	generation := r.blogs.Generation()
+	res, err := r.BlogRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.settled() {
+		r.blogs.SetIf(uint(n), *res, generation)
+	}
+	return res, nil
+}
+
+func (r *CachedBlogRepository) GetAll(ctx context.Context) ([]*models.Blog, error) {
+	if r.readPrimary {
+		return r.BlogRepository.GetAll(ctx)
+	}
+
+	if blogs, ok := r.lists.Get(allBlogs); ok {
+		res := make([]*models.Blog, len(blogs))
+		for i := range blogs {
+			blog := blogs[i]
+			res[i] = &blog
+		}
+		return res, nil
+	}
+
+	generation := r.lists.Generation()
+	res, err := r.BlogRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.settled() {
+		blogs := make([]models.Blog, len(res))
+		for i, blog := range res {
+			blogs[i] = *blog
+		}
+		r.lists.SetIf(allBlogs, blogs, generation)
+	}
+	return res, nil
+}
+
+func (r *CachedBlogRepository) Create(ctx context.Context, m *models.Blog) (*models.Blog, error) {
+	res, err := r.BlogRepository.Create(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	r.publish(ctx, res.ID)
+	return res, nil
+}
+
+func (r *CachedBlogRepository) Update(ctx context.Context, id uint, m *models.Blog) (*models.Blog, error) {
+	res, err := r.BlogRepository.Update(ctx, id, m)
+	r.publish(ctx, id)
+	return res, err
+}
+
+func (r *CachedBlogRepository) Delete(ctx context.Context, id string) error {
+	err := r.BlogRepository.Delete(ctx, id)
+	if n, parseErr := strconv.ParseUint(id, 10, 64); parseErr == nil {
+		r.publish(ctx, uint(n))
+	}
+	return err
+}
+
+func (r *blogCache) Stats() map[string]cache.Stats {
+	return map[string]cache.Stats{
+		"blogs": r.blogs.Stats(),
+		"lists": r.lists.Stats(),
+	}
+}
+
+func (r *CachedBlogRepository) Bypass() BlogRepository {
+	return r.BlogRepository
+}
+
+// Session starts a session of the wrapped repository, if it has them, which
+// shares this cache.
+func (r *CachedBlogRepository) Session(readPrimary bool) BlogRepository {
+	sessioner, ok := r.BlogRepository.(BlogSessioner)
+	if !ok {
+		return r
+	}
+	return &CachedBlogRepository{
+		BlogRepository: sessioner.Session(readPrimary),
+		blogCache:      r.blogCache,
+		readPrimary:    readPrimary,
+	}
+}
+
+// publish invalidates the blog locally, and then on the other replicas.
+func (r *blogCache) publish(ctx context.Context, id uint) {
+	r.invalidate(id)
+	if r.invalidator == nil {
+		return
+	}
+	if err := r.invalidator.Publish(ctx, id); err != nil {
+		r.ioc.Logger.Warn("Failed to invalidate blog", id, "on other replicas:", err)
+	}
+}
+
+// settled reports whether the replicas have likely caught up with the last
+// invalidation, so that what they return may be cached.
+func (r *blogCache) settled() bool {
+	return r.now().Sub(time.Unix(0, r.invalidatedAt.Load())) >= r.replicaLag
+}
+
+func (r *blogCache) invalidate(id uint) {
+	r.invalidatedAt.Store(r.now().UnixNano())
+	if id == 0 {
+		r.blogs.Purge()
+	} else {
+		r.blogs.Delete(id)
+	}
+	r.lists.Purge()
+}
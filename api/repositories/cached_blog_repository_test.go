@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+)
+
+// invalidatorStub delivers every publication back to its subscribers, as
+// though it came from another replica.
+type invalidatorStub struct {
+	published []uint
+	handlers  []func(id uint)
+}
+
+func (i *invalidatorStub) Publish(_ context.Context, id uint) error {
+	i.published = append(i.published, id)
+	return nil
+}
+
+func (i *invalidatorStub) Subscribe(fn func(id uint)) {
+	i.handlers = append(i.handlers, fn)
+}
+
+func TestCachedBlogRepository(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	inner := NewMemoryBlogRepository()
+	invalidator := &invalidatorStub{}
+	config := c.Config.Cache
+	config.ReplicaLag = 0
+	r := NewCachedBlogRepository(&c, inner, config, invalidator)
+
+	blog, err := r.Create(ctx, &models.Blog{Title: "title", Body: "body"})
+	assert.NoError(t, err)
+
+	_, _ = r.GetByID(ctx, "1")
+	cached, _ := r.GetByID(ctx, "1")
+	assert.Equal(t, "body", cached.Body)
+	assert.Equal(t, uint64(1), r.Stats()["blogs"].Hits)
+
+	cached.Body = "modified"
+	again, _ := r.GetByID(ctx, "1")
+	assert.Equal(t, "body", again.Body, "Callers cannot modify the cached blog")
+
+	// A write by another replica is not seen until it is published.
+	_, _ = inner.Update(ctx, blog.ID, &models.Blog{Title: "title", Body: "elsewhere"})
+	stale, _ := r.GetByID(ctx, "1")
+	assert.Equal(t, "body", stale.Body)
+	invalidator.handlers[0](blog.ID)
+	fresh, _ := r.GetByID(ctx, "1")
+	assert.Equal(t, "elsewhere", fresh.Body)
+
+	all, _ := r.GetAll(ctx)
+	assert.Len(t, all, 1)
+	_, _ = r.Create(ctx, &models.Blog{Title: "other", Body: "body"})
+	all, _ = r.GetAll(ctx)
+	assert.Len(t, all, 2, "Writes invalidate the cached list")
+
+	assert.NoError(t, r.Delete(ctx, "1"))
+	_, err = r.GetByID(ctx, "1")
+	assert.Error(t, err, "Deleted blogs are not served from the cache")
+	assert.Equal(t, []uint{1, 2, 1}, invalidator.published)
+
+	assert.Same(t, inner, r.Bypass())
+}
+
+// racingBlogRepository runs write while a blog is read, after the read.
+type racingBlogRepository struct {
+	BlogRepository
+	write func()
+}
+
+func (r racingBlogRepository) GetByID(ctx context.Context, id string) (*models.Blog, error) {
+	res, err := r.BlogRepository.GetByID(ctx, id)
+	r.write()
+	return res, err
+}
+
+func TestCachedBlogRepositoryRace(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	inner := NewMemoryBlogRepository()
+	_, _ = inner.Create(ctx, &models.Blog{Title: "title", Body: "body"})
+
+	var r *CachedBlogRepository
+	config := c.Config.Cache
+	config.ReplicaLag = 0
+	r = NewCachedBlogRepository(&c, racingBlogRepository{inner, func() {
+		_, _ = r.Update(ctx, 1, &models.Blog{Title: "title", Body: "updated"})
+	}}, config, nil)
+
+	stale, _ := r.GetByID(ctx, "1")
+	assert.Equal(t, "body", stale.Body)
+	assert.Equal(t, 0, r.Stats()["blogs"].Size, "A blog read before a write is not cached")
+}
+
+func TestCachedBlogRepositoryReplicaLag(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	now := time.Now()
+	r := NewCachedBlogRepository(&c, NewMemoryBlogRepository(), c.Config.Cache, nil)
+	r.now = func() time.Time { return now }
+
+	_, _ = r.Create(ctx, &models.Blog{Title: "title", Body: "body"})
+	_, _ = r.GetByID(ctx, "1")
+	_, _ = r.GetAll(ctx)
+	assert.Equal(t, 0, r.Stats()["blogs"].Size, "Blogs read right after a write may predate it on a replica")
+	assert.Equal(t, 0, r.Stats()["lists"].Size)
+
+	now = now.Add(c.Config.Cache.ReplicaLag)
+	_, _ = r.GetByID(ctx, "1")
+	_, _ = r.GetAll(ctx)
+	assert.Equal(t, 1, r.Stats()["blogs"].Size)
+	assert.Equal(t, 1, r.Stats()["lists"].Size)
+}
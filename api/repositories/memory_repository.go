@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	apiErrors "example.com/m/v2/errors"
+	"gorm.io/gorm"
+)
+
+// UniqueConstraint makes a MemoryRepository reject two rows with the same key,
+// like a UNIQUE constraint on Column. Key returns the value of the column(s).
+type UniqueConstraint[T any] struct {
+	Column string
+	Key    func(m *T) string
+}
+
+// MemoryRepository implements Repository for any GORM model without a
+// database, for development and tests. It behaves like PostgreSQLRepository
+// where clients can tell: IDs are assigned in sequence, missing rows return
+// gorm.ErrRecordNotFound, rows are soft deleted, and unique constraints apply
+// to soft-deleted rows too.
+//
+// Note: the rows only live as long as the process, and are not shared between
+// replicas.
+type MemoryRepository[T any] struct {
+	mu     sync.RWMutex
+	table  string
+	rows   map[uint]*T
+	lastID uint
+	unique []UniqueConstraint[T]
+}
+
+func NewMemoryRepository[T any](table string, unique ...UniqueConstraint[T]) *MemoryRepository[T] {
+	return &MemoryRepository[T]{
+		table:  table,
+		rows:   make(map[uint]*T),
+		unique: unique,
+	}
+}
+
+func (r *MemoryRepository[T]) Create(_ context.Context, m *T) (*T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(0, m); err != nil {
+		return nil, err
+	}
+
+	r.lastID++
+	now := time.Now()
+	row := modelOf(m)
+	row.ID = r.lastID
+	row.CreatedAt = now
+	row.UpdatedAt = now
+	row.DeletedAt = gorm.DeletedAt{}
+
+	r.rows[row.ID] = clone(m)
+	return m, nil
+}
+
+func (r *MemoryRepository[T]) GetByID(_ context.Context, id string) (*T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n, ok := parseID(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	m, ok := r.find(n)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return clone(m), nil
+}
+
+func (r *MemoryRepository[T]) GetAll(context.Context) ([]*T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(func(*T) bool { return true }), nil
+}
+
+func (r *MemoryRepository[T]) GetByIDs(_ context.Context, ids []uint) ([]*T, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	return r.filter(func(m *T) bool { return wanted[modelOf(m).ID] }), nil
+}
+
+// Update replaces the row, keeping its CreatedAt. Unlike gorm's Save, it does
+// not insert a missing row, but returns gorm.ErrRecordNotFound.
+func (r *MemoryRepository[T]) Update(_ context.Context, id uint, m *T) (*T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if err := r.checkUnique(id, m); err != nil {
+		return nil, err
+	}
+
+	row := modelOf(m)
+	row.ID = id
+	row.CreatedAt = modelOf(existing).CreatedAt
+	row.UpdatedAt = time.Now()
+	row.DeletedAt = gorm.DeletedAt{}
+
+	r.rows[id] = clone(m)
+	return m, nil
+}
+
+// Delete soft deletes the row. Like gorm, deleting a missing row is not an
+// error.
+func (r *MemoryRepository[T]) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n, ok := parseID(id)
+	if !ok {
+		return nil
+	}
+	if m, ok := r.find(n); ok {
+		modelOf(m).DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	}
+	return nil
+}
+
+// find returns the stored row, unless it is missing or soft deleted. The
+// caller must hold the lock.
+func (r *MemoryRepository[T]) find(id uint) (*T, bool) {
+	m, ok := r.rows[id]
+	if !ok || modelOf(m).DeletedAt.Valid {
+		return nil, false
+	}
+	return m, true
+}
+
+// filter returns copies of the rows that are not soft deleted and match keep,
+// ordered by ID. The caller must hold the lock.
+func (r *MemoryRepository[T]) filter(keep func(m *T) bool) []*T {
+	res := []*T{}
+	for _, m := range r.rows {
+		if !modelOf(m).DeletedAt.Valid && keep(m) {
+			res = append(res, clone(m))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return modelOf(res[i]).ID < modelOf(res[j]).ID
+	})
+	return res
+}
+
+// checkUnique checks m against every other row, including soft-deleted ones.
+// id is the row being updated, if any. The caller must hold the lock.
+func (r *MemoryRepository[T]) checkUnique(id uint, m *T) error {
+	for _, constraint := range r.unique {
+		key := constraint.Key(m)
+		for otherID, other := range r.rows {
+			if otherID != id && constraint.Key(other) == key {
+				return &apiErrors.UniqueViolationError{Table: r.table, Column: constraint.Column}
+			}
+		}
+	}
+	return nil
+}
+
+// modelOf returns the gorm.Model embedded in m, like setID does for its ID.
+func modelOf[T any](m *T) *gorm.Model {
+	return reflect.ValueOf(m).Elem().FieldByName("Model").Addr().Interface().(*gorm.Model)
+}
+
+// clone returns a shallow copy of m, so that callers cannot modify the stored
+// rows.
+func clone[T any](m *T) *T {
+	c := *m
+	return &c
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	apiErrors "example.com/m/v2/errors"
+	models "example.com/m/v2/models"
+)
+
+func TestMemoryBlogRepository(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryBlogRepository()
+
+	first, err := r.Create(ctx, &models.Blog{Title: "first", Body: "hello"})
+	assert.NoError(t, err)
+	fingerprint := int64(42)
+	second, err := r.Create(ctx, &models.Blog{Title: "second", Body: "world", Fingerprint: &fingerprint})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), first.ID)
+	assert.Equal(t, uint(2), second.ID, "IDs are assigned in sequence")
+
+	_, err = r.Create(ctx, &models.Blog{Title: "first"})
+	conflict := apiErrors.NewAPIError(apiErrors.WithResource("blog", err))
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+	assert.Equal(t, apiErrors.CodeBlogTitleConflict, conflict.GetErrorCode())
+
+	_, err = r.Update(ctx, second.ID, &models.Blog{Title: "first"})
+	assert.Error(t, err, "Updates may not take the title of another blog")
+
+	updated, err := r.Update(ctx, first.ID, &models.Blog{Title: "first", Body: "updated"})
+	assert.NoError(t, err)
+	assert.Equal(t, first.CreatedAt, updated.CreatedAt)
+
+	assert.NoError(t, r.Delete(ctx, strconv.Itoa(int(first.ID))))
+	_, err = r.GetByID(ctx, strconv.Itoa(int(first.ID)))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Equal(t, http.StatusNotFound, apiErrors.NewAPIError(err).Code)
+
+	all, _ := r.GetAll(ctx)
+	assert.Len(t, all, 1, "Deleted blogs are not listed")
+	_, err = r.Create(ctx, &models.Blog{Title: "first"})
+	assert.Error(t, err, "Titles of deleted blogs stay taken, like in PostgreSQL")
+
+	unhashed, err := r.Create(ctx, &models.Blog{Title: "third", Body: "hello world"})
+	assert.NoError(t, err)
+	assert.NoError(t, r.SetFingerprint(ctx, unhashed.ID, 7))
+	backfilled, _ := r.GetByID(ctx, strconv.Itoa(int(unhashed.ID)))
+	assert.Equal(t, int64(7), *backfilled.Fingerprint)
+	assert.Equal(t, "hello world", backfilled.Body, "The rest of the blog is left alone")
+	assert.Equal(t, unhashed.UpdatedAt, backfilled.UpdatedAt)
+	assert.ErrorIs(t, r.SetFingerprint(ctx, first.ID, 7), gorm.ErrRecordNotFound, "Deleted blogs are not backfilled")
+}
@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+
+	models "example.com/m/v2/models"
+	"gorm.io/gorm"
+)
+
+// AttachmentRepositoryMock follows the same conventions as BlogRepositoryMock.
+// By default, no attachment exists yet, and creating one echoes it back.
+type AttachmentRepositoryMock struct {
+	MockCreate        func(m *models.Attachment) (*models.Attachment, error)
+	MockGetByID       func(id string) (*models.Attachment, error)
+	MockGetByChecksum func(blogID uint, checksum string) (*models.Attachment, error)
+}
+
+func (mock *AttachmentRepositoryMock) Create(_ context.Context, m *models.Attachment) (*models.Attachment, error) {
+	if mock != nil && mock.MockCreate != nil {
+		return mock.MockCreate(m)
+	}
+
+	return m, nil
+}
+
+func (mock *AttachmentRepositoryMock) GetByID(_ context.Context, id string) (*models.Attachment, error) {
+	if mock != nil && mock.MockGetByID != nil {
+		return mock.MockGetByID(id)
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (mock *AttachmentRepositoryMock) GetByChecksum(_ context.Context, blogID uint, checksum string) (*models.Attachment, error) {
+	if mock != nil && mock.MockGetByChecksum != nil {
+		return mock.MockGetByChecksum(blogID, checksum)
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	models "example.com/m/v2/models"
 )
 
@@ -17,18 +19,28 @@ import (
 // The term "override" is used loosely here, as it is not true overriding like
 // in OOP.
 //
+// The `Mock*` fields leave out the context taken by every method, which mocks
+// have no use for.
+//
 // This file contains the default functionality of each mocked method.
 type BlogRepositoryMock struct {
-	MockCreate func(m *models.Blog) (*models.Blog, error)
+	MockCreate   func(m *models.Blog) (*models.Blog, error)
+	MockGetByID  func(id string) (*models.Blog, error)
+	MockGetByIDs func(ids []uint) ([]*models.Blog, error)
+	MockGetAll   func() ([]*models.Blog, error)
+	MockUpdate   func(id uint, m *models.Blog) (*models.Blog, error)
+	MockDelete   func(id string) error
+
+	MockSetFingerprint func(id uint, fingerprint int64) error
 }
 
 // Note: so long as we handle the nil case of `mock`, we are allowed to do the
 // following check, which allows use to override this mock function at will
 // outside of this package.
 //
-// Signature           : func (mock *blogRepositoryMock) Create(m *models.Blog) (*models.Blog, error)
-// Is the exact same as: func Create(mock *blogRepositoryMock, m *models.Blog) (*models.Blog, error)
-func (mock *BlogRepositoryMock) Create(m *models.Blog) (*models.Blog, error) {
+// Signature           : func (mock *blogRepositoryMock) Create(ctx context.Context, m *models.Blog) (*models.Blog, error)
+// Is the exact same as: func Create(mock *blogRepositoryMock, ctx context.Context, m *models.Blog) (*models.Blog, error)
+func (mock *BlogRepositoryMock) Create(_ context.Context, m *models.Blog) (*models.Blog, error) {
 	if mock != nil && mock.MockCreate != nil {
 		return mock.MockCreate(m)
 	}
@@ -41,3 +53,63 @@ func (mock *BlogRepositoryMock) Create(m *models.Blog) (*models.Blog, error) {
 		// UpdatedAt: "2023-05-24T08:19:50.99933Z",
 	}, nil
 }
+
+func (mock *BlogRepositoryMock) GetByID(_ context.Context, id string) (*models.Blog, error) {
+	if mock != nil && mock.MockGetByID != nil {
+		return mock.MockGetByID(id)
+	}
+
+	blog := &models.Blog{
+		Title: "my first blog post",
+		Body:  "hello world!",
+	}
+	blog.ID = 1
+	return blog, nil
+}
+
+func (mock *BlogRepositoryMock) GetByIDs(_ context.Context, ids []uint) ([]*models.Blog, error) {
+	if mock != nil && mock.MockGetByIDs != nil {
+		return mock.MockGetByIDs(ids)
+	}
+
+	blogs := make([]*models.Blog, 0, len(ids))
+	for _, id := range ids {
+		blog := &models.Blog{}
+		blog.ID = id
+		blogs = append(blogs, blog)
+	}
+	return blogs, nil
+}
+
+func (mock *BlogRepositoryMock) GetAll(context.Context) ([]*models.Blog, error) {
+	if mock != nil && mock.MockGetAll != nil {
+		return mock.MockGetAll()
+	}
+
+	return []*models.Blog{}, nil
+}
+
+func (mock *BlogRepositoryMock) SetFingerprint(_ context.Context, id uint, fingerprint int64) error {
+	if mock != nil && mock.MockSetFingerprint != nil {
+		return mock.MockSetFingerprint(id, fingerprint)
+	}
+
+	return nil
+}
+
+func (mock *BlogRepositoryMock) Update(_ context.Context, id uint, m *models.Blog) (*models.Blog, error) {
+	if mock != nil && mock.MockUpdate != nil {
+		return mock.MockUpdate(id, m)
+	}
+
+	m.ID = id
+	return m, nil
+}
+
+func (mock *BlogRepositoryMock) Delete(_ context.Context, id string) error {
+	if mock != nil && mock.MockDelete != nil {
+		return mock.MockDelete(id)
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+
+	"example.com/m/v2/ioc"
+	"gorm.io/gorm"
+)
+
+// The generic counterparts of the blog interfaces. New resources should be
+// built from these rather than declaring their own, e.g. `Getter[models.Tag]`.
+//
+// Every method takes the context of the request, which carries its deadline
+// and trace to the database.
+type Creator[T any] interface {
+	Create(ctx context.Context, m *T) (*T, error)
+}
+
+type Lister[T any] interface {
+	GetAll(ctx context.Context) ([]*T, error)
+}
+
+type Getter[T any] interface {
+	GetByID(ctx context.Context, id string) (*T, error)
+}
+
+type ByIDsGetter[T any] interface {
+	GetByIDs(ctx context.Context, ids []uint) ([]*T, error)
+}
+
+type Updater[T any] interface {
+	Update(ctx context.Context, id uint, m *T) (*T, error)
+}
+
+type Deleter[T any] interface {
+	Delete(ctx context.Context, id string) error
+}
+
+type Repository[T any] interface {
+	Creator[T]
+	Lister[T]
+	Getter[T]
+	ByIDsGetter[T]
+	Updater[T]
+	Deleter[T]
+}
+
+// ReadRouter picks the database that reads are sent to, such as a replica.
+type ReadRouter interface {
+	Replica() *gorm.DB
+}
+
+// PostgreSQLRepository implements Repository for any GORM model, i.e. a struct
+// that embeds gorm.Model. Resource-specific repositories embed it, and add
+// their own queries.
+//
+// Writes go to db. So do reads, unless replicas are configured with
+// WithReplicas, in which case GetByID, GetAll and GetByIDs go to a replica.
+type PostgreSQLRepository[T any] struct {
+	ioc      *ioc.IOC
+	db       *gorm.DB
+	replicas ReadRouter
+
+	// readPrimary is set once a session should read from the primary; it is
+	// nil outside of a session.
+	readPrimary *atomic.Bool
+}
+
+func NewPostgreSQLRepository[T any](c *ioc.IOC, db *gorm.DB) *PostgreSQLRepository[T] {
+	return &PostgreSQLRepository[T]{
+		ioc: c,
+		db:  db,
+	}
+}
+
+// WithReplicas returns a copy of the repository that reads from replicas.
+func (r *PostgreSQLRepository[T]) WithReplicas(replicas ReadRouter) *PostgreSQLRepository[T] {
+	c := *r
+	c.replicas = replicas
+	return &c
+}
+
+// Session returns a copy of the repository for the duration of a request.
+// Its reads go to the replicas until it writes, and to the primary from then
+// on, so that the request reads its own writes. With readPrimary, every read
+// goes to the primary.
+func (r *PostgreSQLRepository[T]) Session(readPrimary bool) *PostgreSQLRepository[T] {
+	c := *r
+	c.readPrimary = &atomic.Bool{}
+	c.readPrimary.Store(readPrimary)
+	return &c
+}
+
+// reader returns the database to read from, in ctx.
+func (r *PostgreSQLRepository[T]) reader(ctx context.Context) *gorm.DB {
+	if r.replicas == nil || (r.readPrimary != nil && r.readPrimary.Load()) {
+		return r.db.WithContext(ctx)
+	}
+	return r.replicas.Replica().WithContext(ctx)
+}
+
+// writer returns the database to write to, in ctx, and makes the session
+// sticky to it.
+func (r *PostgreSQLRepository[T]) writer(ctx context.Context) *gorm.DB {
+	if r.readPrimary != nil {
+		r.readPrimary.Store(true)
+	}
+	return r.db.WithContext(ctx)
+}
+
+func (r *PostgreSQLRepository[T]) Create(ctx context.Context, m *T) (*T, error) {
+	if err := r.writer(ctx).Save(&m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *PostgreSQLRepository[T]) GetByID(ctx context.Context, id string) (*T, error) {
+	n, ok := parseID(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var m T
+	if err := r.reader(ctx).First(&m, n).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *PostgreSQLRepository[T]) GetAll(ctx context.Context) ([]*T, error) {
+	var m []*T
+	if err := r.reader(ctx).Find(&m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *PostgreSQLRepository[T]) GetByIDs(ctx context.Context, ids []uint) ([]*T, error) {
+	m := []*T{}
+	if len(ids) == 0 {
+		return m, nil
+	}
+	if err := r.reader(ctx).Find(&m, ids).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *PostgreSQLRepository[T]) Update(ctx context.Context, id uint, m *T) (*T, error) {
+	// TODO: make immutable by fetching, merging, and persisting
+	setID(m, id)
+	if err := r.writer(ctx).Save(&m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Delete soft deletes the row. Like gorm, deleting a missing row is not an
+// error, and neither is deleting a malformed ID.
+func (r *PostgreSQLRepository[T]) Delete(ctx context.Context, id string) error {
+	n, ok := parseID(id)
+	if !ok {
+		return nil
+	}
+	if err := r.writer(ctx).Delete(new(T), n).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseID parses an ID taken from a request. gorm reads a string given in
+// place of a primary key as a raw SQL condition, so IDs must never be passed
+// to it unparsed.
+func parseID(id string) (uint, bool) {
+	n, err := strconv.ParseUint(id, 10, 64)
+	return uint(n), err == nil
+}
+
+// setID sets the ID that m inherits from gorm.Model. Generic code cannot access
+// the field directly, as type parameters do not expose struct fields.
+func setID[T any](m *T, id uint) {
+	reflect.ValueOf(m).Elem().FieldByName("ID").SetUint(uint64(id))
+}
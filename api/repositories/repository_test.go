@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+)
+
+// replicaStub is a replica that never catches up with the primary.
+type replicaStub struct {
+	db *gorm.DB
+}
+
+func (r replicaStub) Replica() *gorm.DB {
+	return r.db
+}
+
+func openBlogs(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	// Every connection to ":memory:" opens a new, empty database.
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	assert.NoError(t, db.AutoMigrate(&models.Blog{}))
+	return db
+}
+
+func TestPostgreSQLRepositorySession(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	primary, replica := openBlogs(t), openBlogs(t)
+	r := NewPostgreSQLBlogRepository(&c, primary).WithReplicas(replicaStub{replica})
+
+	_, err := r.Create(ctx, &models.Blog{Title: "title", Body: "body"})
+	assert.NoError(t, err)
+	_, err = r.GetByID(ctx, "1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Reads go to the replica")
+
+	session := r.Session(false)
+	_, err = session.GetByID(ctx, "1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "Sessions read from the replica until they write")
+	_, err = session.Create(ctx, &models.Blog{Title: "other", Body: "body"})
+	assert.NoError(t, err)
+	_, err = session.GetByID(ctx, "1")
+	assert.NoError(t, err, "Sessions read their own writes")
+
+	_, err = r.Session(true).GetByID(ctx, "1")
+	assert.NoError(t, err, "Sessions can read from the primary from the start")
+
+	_, err = r.GetByID(ctx, "1")
+	assert.Error(t, err, "Sessions do not affect the repository")
+}
+
+func TestPostgreSQLRepositoryMalformedID(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	r := NewPostgreSQLBlogRepository(&c, openBlogs(t))
+	_, err := r.Create(ctx, &models.Blog{Title: "a", Body: "body"})
+	assert.NoError(t, err)
+	_, err = r.Create(ctx, &models.Blog{Title: "b", Body: "body"})
+	assert.NoError(t, err)
+
+	_, err = r.GetByID(ctx, "title = 'b'")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "IDs are not read as SQL conditions")
+
+	assert.NoError(t, r.Delete(ctx, "1=1"))
+	all, err := r.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, all, 2, "Malformed IDs delete nothing")
+}
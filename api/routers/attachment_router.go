@@ -0,0 +1,19 @@
+package routers
+
+import (
+	"example.com/m/v2/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// BlogAttachments maps the upload of attachments through their blog, at
+// `POST /blogs/:blog_id/attachments/`. It is an option of the blog resource;
+// see InitBlogRouter.
+func BlogAttachments(controller controllers.AttachmentController) ResourceOption {
+	return Nested("attachments", controller, Only(ActionCreate))
+}
+
+// Note: attachments are uploaded through their blog, but served from their
+// own top-level route since the content does not depend on the blog.
+func InitAttachmentRouter(r *gin.Engine, controller controllers.AttachmentController) *gin.RouterGroup {
+	return Resources(r, "attachments", controller, Only(ActionShow))
+}
@@ -1,23 +1,32 @@
 package routers
 
 import (
+	"net/http"
+
 	"example.com/m/v2/controllers"
+	"example.com/m/v2/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-// Note: can use interface controllers.Controller if the default interface
-// suits you. BlogController uses a non-standard interface for ShowWordCount().
-func InitBlogRouter(r *gin.Engine, controller controllers.BlogController) *gin.RouterGroup {
-	routes := r.Group("/blogs")
-	{
-		// NOTE: gin requires trailing slash!
-		routes.GET("/", controller.Index)
-		routes.GET("/new", controller.New)
-		routes.GET("/:id", controller.Show)
-		routes.GET("/:id/words", controller.ShowWordCount)
-		routes.POST("/", controller.Create)
-		routes.PUT("/:id", controller.Update)
-		routes.DELETE("/:id", controller.Delete)
-	}
-	return routes
+// BlogCachePolicy holds the `Cache-Control` header sent by each cacheable
+// blog route, set by `http_cache`. An empty value omits the header for that
+// route.
+type BlogCachePolicy struct {
+	Index         string
+	Show          string
+	ShowWordCount string
+}
+
+// Note: BlogController extends controllers.Controller with member routes.
+// Edit is not mapped, since the API has no forms. opts add the resources that
+// belong to a blog, such as BlogAttachments.
+func InitBlogRouter(r *gin.Engine, controller controllers.BlogController, cache BlogCachePolicy, opts ...ResourceOption) *gin.RouterGroup {
+	return Resources(r, "blogs", controller, append([]ResourceOption{
+		Except(ActionEdit),
+		Use(middleware.Negotiate()),
+		Before(ActionIndex, middleware.CacheControl(cache.Index)),
+		Before(ActionShow, middleware.CacheControl(cache.Show)),
+		Member(http.MethodGet, "words", middleware.CacheControl(cache.ShowWordCount), controller.ShowWordCount),
+		Member(http.MethodGet, "related", controller.ShowRelated),
+	}, opts...)...)
 }
@@ -0,0 +1,54 @@
+package routers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	app "example.com/m/v2/app"
+	"example.com/m/v2/controllers"
+	apiErrors "example.com/m/v2/errors"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+)
+
+// TestBlogRouterProblemAccept checks that a client which only accepts the
+// problem details types gets past the negotiation of the blog routes: it is
+// sent the successful responses, and the problem for the errors.
+func TestBlogRouterProblemAccept(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	c := ioc.NewContainer()
+	a, err := app.New(&c, app.Options{Backend: app.BackendMemory})
+	require.NoError(t, err)
+	_, err = a.BlogRepository.Create(context.Background(), &models.Blog{Title: "title", Body: "body"})
+	require.NoError(t, err)
+
+	r := gin.New()
+	InitBlogRouter(r, controllers.NewBlogController(&c, a.BlogService, a.BlogRepository), BlogCachePolicy{})
+
+	tests := [...]struct {
+		accept      string
+		path        string
+		code        int
+		contentType string
+	}{
+		{apiErrors.MIMEProblemJSON, "/blogs/1", http.StatusOK, "application/json"},
+		{apiErrors.MIMEProblemJSON, "/blogs/99", http.StatusNotFound, apiErrors.MIMEProblemJSON},
+		{apiErrors.MIMEProblemXML, "/blogs/1", http.StatusOK, "application/xml"},
+		{apiErrors.MIMEProblemXML, "/blogs/99", http.StatusNotFound, apiErrors.MIMEProblemXML},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.code, w.Code, "%s with %s", tt.path, tt.accept)
+		assert.Contains(t, w.Header().Get("Content-Type"), tt.contentType, "%s with %s", tt.path, tt.accept)
+	}
+}
@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"example.com/m/v2/controllers"
+	"example.com/m/v2/middleware"
+	"example.com/m/v2/repositories"
+	"github.com/gin-gonic/gin"
+)
+
+// InitCacheRouter serves the statistics of the repository caches at
+// `GET /_cache`. Like `/_routes`, it is only registered when
+// `server.expose_routes` is set.
+func InitCacheRouter(r *gin.Engine, caches repositories.CacheStatser) {
+	r.GET("/_cache", middleware.Negotiate(), middleware.CacheControl("no-store"), controllers.ShowCacheStats(caches))
+}
@@ -0,0 +1,14 @@
+package routers
+
+import (
+	"example.com/m/v2/controllers"
+	"example.com/m/v2/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// The error catalog only changes between releases.
+const errorCatalogCachePolicy = "public, max-age=3600"
+
+func InitErrorRouter(r *gin.Engine) {
+	r.GET("/errors", middleware.Negotiate(), middleware.CacheControl(errorCatalogCachePolicy), controllers.ShowErrorCatalog)
+}
@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"example.com/m/v2/controllers"
+	"example.com/m/v2/middleware"
+	health "example.com/m/v2/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+// InitHealthRouter serves the probes of the server at `GET /healthz/live`,
+// `GET /healthz/ready` and `GET /healthz/startup`.
+func InitHealthRouter(r *gin.Engine, h *health.Registry) {
+	for _, probe := range []health.Probe{health.Live, health.Ready, health.Startup} {
+		r.GET("/healthz/"+string(probe), middleware.CacheControl("no-store"), controllers.ShowHealth(h, probe))
+	}
+}
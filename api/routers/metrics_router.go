@@ -0,0 +1,14 @@
+package routers
+
+import (
+	"example.com/m/v2/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// InitMetricsRouter serves the metrics in reg at `GET /metrics`, in the
+// Prometheus text format.
+func InitMetricsRouter(r *gin.Engine, reg *prometheus.Registry) {
+	r.GET("/metrics", middleware.CacheControl("no-store"), gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
+}
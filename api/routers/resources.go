@@ -0,0 +1,204 @@
+package routers
+
+import (
+	"net/http"
+	"strings"
+
+	"example.com/m/v2/controllers"
+	"github.com/gin-gonic/gin"
+)
+
+// Action is one of the seven CRUD actions of a controllers.Controller.
+type Action string
+
+const (
+	ActionIndex  Action = "index"
+	ActionNew    Action = "new"
+	ActionCreate Action = "create"
+	ActionShow   Action = "show"
+	ActionEdit   Action = "edit"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// route is a route relative to the resource, e.g. "/:id/words".
+type route struct {
+	method   string
+	path     string
+	handlers []gin.HandlerFunc
+}
+
+type resource struct {
+	name       string
+	singular   string
+	controller controllers.Controller
+	actions    map[Action]bool
+	before     map[Action][]gin.HandlerFunc
+	middleware []gin.HandlerFunc
+	extra      []route
+	nested     []*resource
+}
+
+// ResourceOption customizes the routes created by Resources.
+type ResourceOption func(*resource)
+
+// Only maps the given actions, and no others.
+func Only(actions ...Action) ResourceOption {
+	return func(r *resource) {
+		r.actions = make(map[Action]bool, len(actions))
+		for _, action := range actions {
+			r.actions[action] = true
+		}
+	}
+}
+
+// Except maps every action but the given ones.
+func Except(actions ...Action) ResourceOption {
+	return func(r *resource) {
+		for _, action := range actions {
+			delete(r.actions, action)
+		}
+	}
+}
+
+// Use adds middleware to every route of the resource, including its member,
+// collection and nested routes.
+func Use(middleware ...gin.HandlerFunc) ResourceOption {
+	return func(r *resource) {
+		r.middleware = append(r.middleware, middleware...)
+	}
+}
+
+// Before adds middleware to a single action, e.g. a cache policy to `show`.
+func Before(action Action, middleware ...gin.HandlerFunc) ResourceOption {
+	return func(r *resource) {
+		r.before[action] = append(r.before[action], middleware...)
+	}
+}
+
+// Member adds a route acting on a single resource, e.g. `GET /blogs/:id/words`
+// for Member(http.MethodGet, "words", ...).
+func Member(method, path string, handlers ...gin.HandlerFunc) ResourceOption {
+	return func(r *resource) {
+		r.extra = append(r.extra, route{method, "/:id/" + path, handlers})
+	}
+}
+
+// Collection adds a route acting on every resource, e.g. `GET /blogs/search`
+// for Collection(http.MethodGet, "search", ...).
+func Collection(method, path string, handlers ...gin.HandlerFunc) ResourceOption {
+	return func(r *resource) {
+		r.extra = append(r.extra, route{method, "/" + path, handlers})
+	}
+}
+
+// Singular names the resource in the `:<singular>_id` parameter of its nested
+// routes. It defaults to the name without its trailing "s".
+func Singular(singular string) ResourceOption {
+	return func(r *resource) {
+		r.singular = singular
+	}
+}
+
+// Nested maps a resource that belongs to this one, e.g. comments of a blog at
+// `/blogs/:blog_id/comments`. The nested handlers read the ID of the parent
+// from c.Param("blog_id"), and their own ID from c.Param("id").
+func Nested(name string, controller controllers.Controller, opts ...ResourceOption) ResourceOption {
+	return func(r *resource) {
+		r.nested = append(r.nested, newResource(name, controller, opts))
+	}
+}
+
+// Resources maps the seven actions of a controller to routes, the way Rails
+// does:
+//
+//	GET    /blogs/          Index
+//	GET    /blogs/new       New
+//	POST   /blogs/          Create
+//	GET    /blogs/:id       Show
+//	GET    /blogs/:id/edit  Edit
+//	PUT    /blogs/:id       Update
+//	DELETE /blogs/:id       Delete
+//
+// See: https://guides.rubyonrails.org/routing.html#crud-verbs-and-actions
+func Resources(r gin.IRouter, name string, controller controllers.Controller, opts ...ResourceOption) *gin.RouterGroup {
+	return newResource(name, controller, opts).register(r, nil)
+}
+
+func newResource(name string, controller controllers.Controller, opts []ResourceOption) *resource {
+	r := &resource{
+		name:       name,
+		singular:   strings.TrimSuffix(name, "s"),
+		controller: controller,
+		actions: map[Action]bool{
+			ActionIndex: true, ActionNew: true, ActionCreate: true, ActionShow: true,
+			ActionEdit: true, ActionUpdate: true, ActionDelete: true,
+		},
+		before: make(map[Action][]gin.HandlerFunc),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// register adds the routes of the resource to parent. parents holds the
+// parameter names of the resources it is nested in, outermost first.
+func (r *resource) register(parent gin.IRouter, parents []string) *gin.RouterGroup {
+	routes := parent.Group("/" + r.name)
+	if len(parents) > 0 {
+		routes.Use(renameParams(parents))
+	}
+	routes.Use(r.middleware...)
+
+	actions := []struct {
+		action  Action
+		method  string
+		path    string
+		handler gin.HandlerFunc
+	}{
+		// NOTE: gin requires trailing slash!
+		{ActionIndex, http.MethodGet, "/", r.controller.Index},
+		{ActionNew, http.MethodGet, "/new", r.controller.New},
+		{ActionCreate, http.MethodPost, "/", r.controller.Create},
+		{ActionShow, http.MethodGet, "/:id", r.controller.Show},
+		{ActionEdit, http.MethodGet, "/:id/edit", r.controller.Edit},
+		{ActionUpdate, http.MethodPut, "/:id", r.controller.Update},
+		{ActionDelete, http.MethodDelete, "/:id", r.controller.Delete},
+	}
+	for _, a := range actions {
+		if r.actions[a.action] {
+			routes.Handle(a.method, a.path, append(r.before[a.action], a.handler)...)
+		}
+	}
+
+	for _, extra := range r.extra {
+		routes.Handle(extra.method, extra.path, extra.handlers...)
+	}
+
+	// gin does not allow `/blogs/:blog_id/comments` next to `/blogs/:id`, so
+	// nested routes are registered under `/blogs/:id/comments`, and the
+	// parameter is renamed when the request is handled.
+	for _, nested := range r.nested {
+		nested.register(routes.Group("/:id"), append(parents[:len(parents):len(parents)], r.singular+"_id"))
+	}
+	return routes
+}
+
+// renameParams renames the leading `id` parameters of a nested route to the
+// parameter names of its parents, e.g. the first `id` of
+// `/blogs/:id/comments/:id` to `blog_id`.
+func renameParams(parents []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		i := 0
+		for j := range c.Params {
+			if i == len(parents) {
+				break
+			}
+			if c.Params[j].Key == "id" {
+				c.Params[j].Key = parents[i]
+				i++
+			}
+		}
+	}
+}
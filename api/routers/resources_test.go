@@ -0,0 +1,70 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// controllerStub responds with the action it handled, and the route
+// parameters it was given.
+type controllerStub struct{}
+
+func (controllerStub) respond(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.String(http.StatusOK, action+" "+c.Param("blog_id")+" "+c.Param("id"))
+	}
+}
+
+func (s controllerStub) Index(c *gin.Context)  { s.respond("index")(c) }
+func (s controllerStub) Show(c *gin.Context)   { s.respond("show")(c) }
+func (s controllerStub) New(c *gin.Context)    { s.respond("new")(c) }
+func (s controllerStub) Create(c *gin.Context) { s.respond("create")(c) }
+func (s controllerStub) Edit(c *gin.Context)   { s.respond("edit")(c) }
+func (s controllerStub) Update(c *gin.Context) { s.respond("update")(c) }
+func (s controllerStub) Delete(c *gin.Context) { s.respond("delete")(c) }
+
+func TestResources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	stub := controllerStub{}
+	Resources(r, "blogs", stub,
+		Except(ActionEdit),
+		Member(http.MethodGet, "words", stub.respond("words")),
+		Collection(http.MethodGet, "search", stub.respond("search")),
+		Nested("comments", stub, Only(ActionIndex, ActionShow)),
+	)
+
+	tests := [...]struct {
+		method string
+		path   string
+		code   int
+		body   string
+	}{
+		{http.MethodGet, "/blogs/", http.StatusOK, "index  "},
+		{http.MethodGet, "/blogs/new", http.StatusOK, "new  "},
+		{http.MethodPost, "/blogs/", http.StatusOK, "create  "},
+		{http.MethodGet, "/blogs/1", http.StatusOK, "show  1"},
+		{http.MethodGet, "/blogs/1/edit", http.StatusNotFound, ""},
+		{http.MethodPut, "/blogs/1", http.StatusOK, "update  1"},
+		{http.MethodDelete, "/blogs/1", http.StatusOK, "delete  1"},
+		{http.MethodGet, "/blogs/1/words", http.StatusOK, "words  1"},
+		{http.MethodGet, "/blogs/search", http.StatusOK, "search  "},
+		{http.MethodGet, "/blogs/1/comments/", http.StatusOK, "index 1 "},
+		{http.MethodGet, "/blogs/1/comments/2", http.StatusOK, "show 1 2"},
+		{http.MethodDelete, "/blogs/1/comments/2", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		assert.Equal(t, tt.code, w.Code, "%s %s", tt.method, tt.path)
+		if tt.code == http.StatusOK {
+			assert.Equal(t, tt.body, w.Body.String(), "%s %s", tt.method, tt.path)
+		}
+	}
+}
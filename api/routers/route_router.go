@@ -0,0 +1,14 @@
+package routers
+
+import (
+	"example.com/m/v2/controllers"
+	"example.com/m/v2/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// InitRouteRouter serves the routes of the engine at `GET /_routes`. The
+// routes reveal the handlers, so it is only registered when
+// `server.expose_routes` is set.
+func InitRouteRouter(r *gin.Engine) {
+	r.GET("/_routes", middleware.Negotiate(), controllers.ShowRoutes(r))
+}
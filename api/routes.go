@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	app "example.com/m/v2/app"
+	ioc "example.com/m/v2/ioc"
+	"github.com/gin-gonic/gin"
+)
+
+// runRoutes implements `server routes`, which lists the routes of the API
+// without serving it, as registered with the configuration. The routes are
+// registered on the memory backend, so that no database is needed; `/_cache`
+// is thus missing, since only the postgres backend caches blogs.
+func runRoutes(c *ioc.IOC, args []string) error {
+	a, err := app.New(c, app.Options{Backend: app.BackendMemory})
+	if err != nil {
+		return err
+	}
+
+	// gin prints every route as it is registered, in debug mode.
+	gin.DefaultWriter = io.Discard
+	for _, route := range newRouter(a).Routes() {
+		fmt.Printf("%-7s %-40s %s\n", route.Method, route.Path, route.Handler)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"flag"
+
+	app "example.com/m/v2/app"
+	fixtures "example.com/m/v2/fixtures"
+	ioc "example.com/m/v2/ioc"
+)
+
+// setupSeed implements `server seed`, which creates the example blogs embedded
+// in the binary, the blogs of a fixture file, or blogs made up by a generator.
+// Blogs whose title is taken are left as they are, so it is safe to run on
+// every deploy.
+func setupSeed(flags *flag.FlagSet) runFunc {
+	file := flags.String("file", "", "YAML or JSON fixture file to load, instead of the example blogs")
+	generate := flags.Int("generate", 0, "number of blogs to generate, instead of loading the example blogs")
+	seed := flags.Int64("seed", 1, "seed of the generator; the same seed generates the same blogs")
+
+	return func(c *ioc.IOC, args []string) error {
+		var set *fixtures.Set
+		var err error
+		switch {
+		case *file != "" && *generate > 0:
+			return errors.New("-file and -generate cannot be used together")
+		case *file != "":
+			set, err = fixtures.ReadFile(*file)
+		case *generate > 0:
+			set = fixtures.Generate(*seed, *generate)
+		default:
+			set, err = fixtures.Embedded()
+		}
+		if err != nil {
+			return err
+		}
+
+		a, err := app.New(c, app.Options{})
+		if err != nil {
+			return err
+		}
+		return load(a, set)
+	}
+}
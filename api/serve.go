@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	app "example.com/m/v2/app"
+	controllers "example.com/m/v2/controllers"
+	ioc "example.com/m/v2/ioc"
+	middleware "example.com/m/v2/middleware"
+	repositories "example.com/m/v2/repositories"
+	routers "example.com/m/v2/routers"
+	tracing "example.com/m/v2/tracing"
+	"github.com/gin-gonic/gin"
+)
+
+// setupServe implements `server serve`, which serves the API on
+// `server.port` until it receives SIGTERM or SIGINT.
+func setupServe(flags *flag.FlagSet) runFunc {
+	allowSchemaMismatch := flags.Bool("allow-schema-mismatch", false, "start even if the schema is not at the version that this binary expects")
+
+	return func(c *ioc.IOC, args []string) error {
+		// Set up tracing first, so that the app traces, and so that the
+		// spans of every other component are flushed before it stops.
+		if err := tracing.Setup(c); err != nil {
+			return err
+		}
+
+		a, err := app.New(c, app.Options{AllowSchemaMismatch: *allowSchemaMismatch})
+		if err != nil {
+			return err
+		}
+		if err := a.BlogService.IndexAll(context.Background(), a.BlogRepository); err != nil {
+			c.Logger.Error("Failed to index blogs for related posts and duplicates:", err)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+		defer cancel()
+
+		// The server is appended last, so that it is the first to stop.
+		failed := make(chan error, 1)
+		appendServer(c, newRouter(a), failed)
+		if err := c.Lifecycle.Start(ctx); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			c.Logger.Info("Shutting down")
+		case err = <-failed:
+			c.Logger.Error("The server failed:", err)
+		}
+
+		// Stop on a fresh context, as ctx is done.
+		config := c.Config.Server
+		stopCtx, stop := context.WithTimeout(context.Background(), config.DrainPeriod+config.ShutdownTimeout)
+		defer stop()
+		if stopErr := c.Lifecycle.Stop(stopCtx); err == nil {
+			err = stopErr
+		}
+		return err
+	}
+}
+
+// appendServer appends the HTTP server to the lifecycle. Once the lifecycle is
+// stopping, and thus no longer ready, the server keeps serving for the drain
+// period, and then waits for the requests in flight. Errors of the server
+// while it serves are sent to failed.
+func appendServer(c *ioc.IOC, handler http.Handler, failed chan<- error) {
+	config := c.Config.Server
+	srv := &http.Server{
+		Addr:         ":" + strconv.Itoa(config.Port),
+		Handler:      handler,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		IdleTimeout:  config.IdleTimeout,
+	}
+
+	c.Lifecycle.Append(ioc.Hook{
+		Name: "HTTP server",
+		OnStart: func(context.Context) error {
+			// Listen before returning, so that a port in use fails the start.
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			c.Logger.Info("Listening on", srv.Addr)
+			go func() {
+				if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+					failed <- err
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			c.Logger.Info("Draining for", config.DrainPeriod)
+			select {
+			case <-time.After(config.DrainPeriod):
+			case <-ctx.Done():
+			}
+			return srv.Shutdown(ctx)
+		},
+	})
+}
+
+// newRouter registers every route of the API.
+func newRouter(a *app.App) *gin.Engine {
+	gin.SetMode(a.IOC.Config.Server.Mode)
+	r := gin.Default()
+
+	// Trace and measure every request, including those that fail in later
+	// middleware.
+	r.Use(middleware.Tracing(a.IOC))
+	r.Use(middleware.Metrics(a.IOC))
+
+	// Init error handler
+	r.Use(middleware.RequestID())
+	r.Use(middleware.ErrorHandler(a.IOC))
+
+	// Set `server.problem_details` to render all errors as RFC 7807 problem
+	// details, instead of only for clients that ask for them.
+	r.Use(middleware.ProblemDetails(a.IOC.Config.Server.ProblemDetails))
+
+	r.GET("/ping", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "pong",
+		})
+	})
+
+	blogController := controllers.NewBlogController(
+		a.IOC,
+		a.BlogService,
+		a.BlogRepository,
+	)
+
+	attachmentController := controllers.NewAttachmentController(
+		a.IOC,
+		a.AttachmentService,
+		a.AttachmentRepository,
+		a.BlogRepository,
+		a.IOC.Config.Attachments.MaxSize,
+	)
+
+	routers.InitBlogRouter(r, blogController, routers.BlogCachePolicy{
+		Index:         a.IOC.Config.HTTPCache.Index,
+		Show:          a.IOC.Config.HTTPCache.Show,
+		ShowWordCount: a.IOC.Config.HTTPCache.Words,
+	}, routers.BlogAttachments(attachmentController))
+	routers.InitAttachmentRouter(r, attachmentController)
+	routers.InitErrorRouter(r)
+	routers.InitHealthRouter(r, a.IOC.Health)
+	routers.InitMetricsRouter(r, a.IOC.Metrics)
+
+	// The debugging endpoints reveal the internals of the server.
+	if a.IOC.Config.Server.ExposeRoutes {
+		routers.InitRouteRouter(r)
+		if caches, ok := a.BlogRepository.(repositories.CacheStatser); ok {
+			routers.InitCacheRouter(r, caches)
+		}
+	}
+
+	return r
+}
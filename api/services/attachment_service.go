@@ -0,0 +1,122 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	repositories "example.com/m/v2/repositories"
+	"gorm.io/gorm"
+)
+
+// AllowedAttachmentTypes are the content types accepted for attachments. The
+// type is sniffed from the content itself; the client's claim is ignored.
+var AllowedAttachmentTypes = map[string]bool{
+	"image/gif":  true,
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+const attachmentResource = "attachment"
+
+// attachmentService handles business logic related to blog attachments
+type attachmentService struct {
+	ioc     *ioc.IOC
+	maxSize int64
+}
+
+type AttachmentService interface {
+	Create(ctx context.Context, m *dtos.CreateAttachmentRequest, r repositories.AttachmentRepository, b repositories.SingleBlogGetter) (*models.Attachment, error)
+	GetByID(ctx context.Context, id string, r repositories.SingleAttachmentGetter) (*models.Attachment, error)
+	Open(m *models.Attachment) (io.ReadCloser, error)
+}
+
+func NewAttachmentService(c *ioc.IOC, maxSize int64) *attachmentService {
+	return &attachmentService{
+		ioc:     c,
+		maxSize: maxSize,
+	}
+}
+
+// Create stores the uploaded content and records its metadata against the
+// blog. Uploading the same content to the same blog twice returns the existing
+// attachment instead of creating a new one.
+func (s attachmentService) Create(ctx context.Context, m *dtos.CreateAttachmentRequest, r repositories.AttachmentRepository, b repositories.SingleBlogGetter) (*models.Attachment, error) {
+	blog, err := b.GetByID(ctx, m.BlogID)
+	if err != nil {
+		return nil, apiErrors.WithResource(blogResource, err)
+	}
+
+	// Read one byte past the limit so that oversized content can be detected.
+	data, err := io.ReadAll(io.LimitReader(m.Content, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, fmt.Errorf("%w: attachments may not exceed %d bytes", apiErrors.IsPayloadTooLargeError, s.maxSize)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !AllowedAttachmentTypes[contentType] {
+		return nil, fmt.Errorf("%w: %s", apiErrors.IsUnsupportedMediaTypeError, contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+
+	existing, err := r.GetByChecksum(ctx, blog.ID, checksum)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Objects are keyed by their checksum, so the same content attached to
+	// several blogs is only stored once.
+	exists, err := s.ioc.Storage.Exists(checksum)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := s.ioc.Storage.Put(checksum, bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := r.Create(ctx, &models.Attachment{
+		BlogID:      blog.ID,
+		Filename:    filepath.Base(m.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Checksum:    checksum,
+	})
+	if err != nil {
+		return nil, apiErrors.WithResource(attachmentResource, err)
+	}
+	return res, nil
+}
+
+func (s attachmentService) GetByID(ctx context.Context, id string, r repositories.SingleAttachmentGetter) (*models.Attachment, error) {
+	res, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, apiErrors.WithResource(attachmentResource, err)
+	}
+	return res, nil
+}
+
+// Open returns the content of the attachment. The caller must close it.
+func (s attachmentService) Open(m *models.Attachment) (io.ReadCloser, error) {
+	return s.ioc.Storage.Get(m.Checksum)
+}
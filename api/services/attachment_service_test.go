@@ -0,0 +1,114 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	storage "example.com/m/v2/pkg/storage"
+	mocks "example.com/m/v2/repositories/mocks"
+)
+
+// A minimal PNG header is enough for content type sniffing.
+var png = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+func TestCreateAttachment(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	c.Storage = storage.NewLocalStorage(t.TempDir())
+	s := NewAttachmentService(&c, 64)
+
+	existing := &models.Attachment{Filename: "existing.png"}
+
+	tests := [...]struct {
+		name     string
+		content  []byte
+		store    *mocks.AttachmentRepositoryMock
+		blogs    *mocks.BlogRepositoryMock
+		expected error
+	}{
+		{
+			"HappyPath",
+			png,
+			nil,
+			nil,
+			nil,
+		},
+		{
+			"BlogNotFound",
+			png,
+			nil,
+			&mocks.BlogRepositoryMock{
+				MockGetByID: func(id string) (*models.Blog, error) {
+					return nil, gorm.ErrRecordNotFound
+				},
+			},
+			gorm.ErrRecordNotFound,
+		},
+		{
+			"TooLarge",
+			append(png, make([]byte, 64)...),
+			nil,
+			nil,
+			apiErrors.IsPayloadTooLargeError,
+		},
+		{
+			"NotAnImage",
+			[]byte("<html></html>"),
+			nil,
+			nil,
+			apiErrors.IsUnsupportedMediaTypeError,
+		},
+		{
+			"Duplicate",
+			png,
+			&mocks.AttachmentRepositoryMock{
+				MockGetByChecksum: func(blogID uint, checksum string) (*models.Attachment, error) {
+					return existing, nil
+				},
+				MockCreate: func(m *models.Attachment) (*models.Attachment, error) {
+					return nil, errors.New("should not be called")
+				},
+			},
+			nil,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.Create(ctx, &dtos.CreateAttachmentRequest{
+				BlogID:   "1",
+				Filename: "../../image.png",
+				Content:  bytes.NewReader(tt.content),
+			}, tt.store, tt.blogs)
+
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, tt.expected)
+				return
+			}
+
+			assert.NoError(t, err)
+			if tt.store != nil {
+				assert.Same(t, existing, res)
+				return
+			}
+
+			assert.Equal(t, "image.png", res.Filename)
+			assert.Equal(t, "image/png", res.ContentType)
+			assert.Equal(t, uint(1), res.BlogID)
+
+			exists, err := c.Storage.Exists(res.Checksum)
+			assert.NoError(t, err)
+			assert.True(t, exists)
+		})
+	}
+}
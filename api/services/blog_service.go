@@ -1,76 +1,222 @@
 package services
 
 import (
+	"context"
+	"strconv"
+
 	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
 	"example.com/m/v2/ioc"
 	models "example.com/m/v2/models"
+	metrics "example.com/m/v2/pkg/metrics"
+	similarity "example.com/m/v2/pkg/similarity"
 	repositories "example.com/m/v2/repositories"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// blogResource refines the codes of errors returned by the repository, e.g.
+// into `blog.not_found`.
+const blogResource = "blog"
+
 // BlogService handles business logic related to blogs
 type blogService struct {
-	ioc *ioc.IOC
+	*CRUDService[models.Blog, dtos.CreateBlogRequest, dtos.UpdateBlogRequest]
+	related      *similarity.Index
+	fingerprints *similarity.Fingerprints
+	duplicates   DuplicatePolicy
+	writes       *prometheus.CounterVec
 }
 
 type BlogService interface {
-	Create(m *dtos.CreateBlogRequest, r repositories.BlogCreator) (*models.Blog, error)
-	GetByID(id string, r repositories.SingleBlogGetter) (*models.Blog, error)
-	GetAll(r repositories.MultiBlogGetter) ([]*models.Blog, error)
-	Update(id uint, m *dtos.UpdateBlogRequest, r repositories.BlogUpdater) (*models.Blog, error)
-	Delete(id string, r repositories.BlogDeleter) error
+	Create(ctx context.Context, m *dtos.CreateBlogRequest, r repositories.BlogCreator) (*models.Blog, error)
+	GetByID(ctx context.Context, id string, r repositories.SingleBlogGetter) (*models.Blog, error)
+	GetAll(ctx context.Context, r repositories.MultiBlogGetter) ([]*models.Blog, error)
+	Update(ctx context.Context, id uint, m *dtos.UpdateBlogRequest, r repositories.BlogUpdater) (*models.Blog, error)
+	Delete(ctx context.Context, id string, r repositories.BlogDeleter) error
+	GetRelated(ctx context.Context, id string, limit int, r repositories.RelatedBlogGetter) ([]*dtos.RelatedBlogResponse, error)
+	IndexAll(ctx context.Context, r repositories.BlogIndexer) error
 }
 
-func NewBlogService(c *ioc.IOC) *blogService {
-	return &blogService{
-		ioc: c,
+// NewBlogService keeps the related index, and the fingerprints that blogs are
+// checked against for near duplicates, up to date as blogs are written through
+// it. Call IndexAll on startup to index the blogs that already exist.
+//
+// Note: each replica holds its own index, and only sees the writes that it
+// serves itself until it is restarted.
+//
+// The blogs created, updated and deleted are counted by
+// `blogger_blog_writes_total`.
+func NewBlogService(c *ioc.IOC, related *similarity.Index, duplicates DuplicatePolicy) *blogService {
+	s := &blogService{
+		related:      related,
+		fingerprints: similarity.NewFingerprints(),
+		duplicates:   duplicates,
+		writes: metrics.Register(c.Metrics, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Name:      "blog_writes_total",
+			Help:      "Number of blogs created, updated and deleted.",
+		}, []string{"operation"})),
+	}
+	// Export every operation from the start, so that rates are defined.
+	for _, operation := range []string{"create", "update", "delete"} {
+		s.writes.WithLabelValues(operation)
 	}
+	s.CRUDService = NewCRUDService(c, blogResource, s.mapCreateBlogRequestToModel, s.mapUpdateBlogRequestToModel)
+	return s
 }
 
 // Note: the use of the smaller repository interfaces allow us to have slimmer
-// mocks.
-func (s blogService) Create(m *dtos.CreateBlogRequest, r repositories.BlogCreator) (*models.Blog, error) {
-	res, err := r.Create(s.mapCreateBlogRequestToModel(*m))
+// mocks. GetByID and GetAll are inherited from CRUDService as is; the writes
+// also maintain the related index and check for near duplicates.
+func (s blogService) Create(ctx context.Context, m *dtos.CreateBlogRequest, r repositories.BlogCreator) (*models.Blog, error) {
+	model := s.mapCreateBlogRequestToModel(*m)
+	if err := s.checkDuplicate(0, model); err != nil {
+		return nil, err
+	}
+
+	res, err := s.CreateModel(ctx, model, r)
 	if err != nil {
 		return nil, err
 	}
+	s.writes.WithLabelValues("create").Inc()
+	s.related.Upsert(s.mapModelToDocument(res))
+	s.setFingerprint(res.ID, model.Fingerprint)
 	return res, nil
 }
 
-func (s blogService) GetByID(id string, r repositories.SingleBlogGetter) (*models.Blog, error) {
-	res, err := r.GetByID(id)
+// TODO: should id's be string or uint? Make consistent everywhere else!
+func (s blogService) Update(ctx context.Context, id uint, m *dtos.UpdateBlogRequest, r repositories.BlogUpdater) (*models.Blog, error) {
+	model := s.mapUpdateBlogRequestToModel(*m)
+	if err := s.checkDuplicate(id, model); err != nil {
+		return nil, err
+	}
+
+	res, err := s.UpdateModel(ctx, id, model, r)
 	if err != nil {
 		return nil, err
 	}
+	s.writes.WithLabelValues("update").Inc()
+	s.related.Upsert(s.mapModelToDocument(res))
+	s.setFingerprint(res.ID, model.Fingerprint)
 	return res, nil
 }
 
-func (s blogService) GetAll(r repositories.MultiBlogGetter) ([]*models.Blog, error) {
-	res, err := r.GetAll()
+func (s blogService) Delete(ctx context.Context, id string, r repositories.BlogDeleter) error {
+	if err := s.CRUDService.Delete(ctx, id, r); err != nil {
+		return err
+	}
+	s.writes.WithLabelValues("delete").Inc()
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		s.related.Remove(uint(n))
+		s.fingerprints.Remove(uint(n))
+	}
+	return nil
+}
+
+// GetRelated returns up to limit blogs whose bodies are most similar to the
+// given blog, best match first.
+func (s blogService) GetRelated(ctx context.Context, id string, limit int, r repositories.RelatedBlogGetter) ([]*dtos.RelatedBlogResponse, error) {
+	blog, err := r.GetByID(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, apiErrors.WithResource(blogResource, err)
+	}
+
+	// The blog may have been written by another replica.
+	if !s.related.Has(blog.ID) {
+		s.related.Upsert(s.mapModelToDocument(blog))
+	}
+
+	matches := s.related.Related(blog.ID, limit)
+	ids := make([]uint, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+
+	blogs, err := r.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, apiErrors.WithResource(blogResource, err)
+	}
+
+	byID := make(map[uint]*models.Blog, len(blogs))
+	for _, b := range blogs {
+		byID[b.ID] = b
+	}
+
+	// Keep the ranking of the index; skip blogs deleted by another replica.
+	res := make([]*dtos.RelatedBlogResponse, 0, len(matches))
+	for _, match := range matches {
+		if b, ok := byID[match.ID]; ok {
+			res = append(res, &dtos.RelatedBlogResponse{Blog: *b, Score: match.Score})
+		}
 	}
 	return res, nil
 }
 
-// TODO: should id's be string or uint? Make consistent everywhere else!
-func (s blogService) Update(id uint, m *dtos.UpdateBlogRequest, r repositories.BlogUpdater) (*models.Blog, error) {
-	res, err := r.Update(id, s.mapUpdateBlogRequestToModel(*m))
+// IndexAll (re)builds the related index and the fingerprints from every blog
+// in the repository. Blogs written before blogs had a fingerprint are given
+// one; if that fails for some, they are still indexed, and the first error is
+// returned.
+func (s blogService) IndexAll(ctx context.Context, r repositories.BlogIndexer) error {
+	blogs, err := r.GetAll(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return res, nil
+
+	var backfillErr error
+	for _, blog := range blogs {
+		s.related.Upsert(s.mapModelToDocument(blog))
+
+		fingerprint := blog.Fingerprint
+		if fingerprint == nil {
+			if fingerprint = fingerprintOf(blog.Body); fingerprint != nil {
+				if err := r.SetFingerprint(ctx, blog.ID, *fingerprint); err != nil && backfillErr == nil {
+					backfillErr = err
+				}
+			}
+		}
+		s.setFingerprint(blog.ID, fingerprint)
+	}
+	return backfillErr
 }
 
-func (s blogService) Delete(id string, r repositories.BlogDeleter) error {
-	err := r.Delete(id)
-	return err
+// checkDuplicate compares the fingerprint of m against every other blog, and
+// applies the duplicate policy to the closest match. id is the blog being
+// updated, if any, which is excluded from the comparison. A blog without a
+// fingerprint is never a near-duplicate.
+func (s blogService) checkDuplicate(id uint, m *models.Blog) error {
+	if s.duplicates.Action == DuplicateActionIgnore || m.Fingerprint == nil {
+		return nil
+	}
+
+	other, score, ok := s.fingerprints.Closest(uint64(*m.Fingerprint), id)
+	if !ok || score < s.duplicates.Threshold {
+		return nil
+	}
+
+	closest := &apiErrors.NearDuplicateError{ConflictingID: other, Similarity: score}
+	if s.duplicates.Action == DuplicateActionWarn {
+		s.ioc.Logger.Warn(closest.Error())
+		return nil
+	}
+	return closest
+}
+
+// setFingerprint records the fingerprint of a blog that was written, or forgets
+// it if the blog has none (any longer).
+func (s blogService) setFingerprint(id uint, fingerprint *int64) {
+	if fingerprint == nil {
+		s.fingerprints.Remove(id)
+		return
+	}
+	s.fingerprints.Set(id, uint64(*fingerprint))
 }
 
 func (s blogService) mapCreateBlogRequestToModel(request dtos.CreateBlogRequest) *models.Blog {
 	// Perform mapping or conversion from DTO to domain model
 	model := &models.Blog{
-		Title: request.Title,
-		Body:  request.Body,
+		Title:       request.Title,
+		Body:        request.Body,
+		Fingerprint: fingerprintOf(request.Body),
 	}
 	return model
 }
@@ -78,8 +224,26 @@ func (s blogService) mapCreateBlogRequestToModel(request dtos.CreateBlogRequest)
 func (s blogService) mapUpdateBlogRequestToModel(request dtos.UpdateBlogRequest) *models.Blog {
 	// Perform mapping or conversion from DTO to domain model
 	model := &models.Blog{
-		Title: request.Title,
-		Body:  request.Body,
+		Title:       request.Title,
+		Body:        request.Body,
+		Fingerprint: fingerprintOf(request.Body),
 	}
 	return model
 }
+
+// fingerprintOf returns the SimHash of body, or nil if body has no words.
+func fingerprintOf(body string) *int64 {
+	f, ok := similarity.SimHash(body)
+	if !ok {
+		return nil
+	}
+	signed := int64(f)
+	return &signed
+}
+
+func (s blogService) mapModelToDocument(m *models.Blog) similarity.Document {
+	return similarity.Document{
+		ID:   m.ID,
+		Text: m.Body,
+	}
+}
@@ -1,18 +1,25 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"strconv"
 	"testing"
 
 	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
 	"example.com/m/v2/ioc"
 	models "example.com/m/v2/models"
+	similarity "example.com/m/v2/pkg/similarity"
+	repositories "example.com/m/v2/repositories"
 	mocks "example.com/m/v2/repositories/mocks"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestCreate(t *testing.T) {
+	ctx := context.Background()
 	c := ioc.NewContainer()
-	s := NewBlogService(&c)
+	s := NewBlogService(&c, similarity.NewIndex(), DefaultDuplicatePolicy)
 	d := &dtos.CreateBlogRequest{
 		Title: "my first blog post",
 		Body:  "hello world!",
@@ -47,10 +54,154 @@ func TestCreate(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := s.Create(d, tt.store)
+			_, err := s.Create(ctx, d, tt.store)
 			if tt.shouldErr && err == nil {
 				t.Error("expected error but got <nil>")
 			}
 		})
 	}
+	// Only the blogs actually created are counted.
+	if n := testutil.ToFloat64(s.writes.WithLabelValues("create")); n != 1 {
+		t.Errorf("expected 1 blog created, got %v", n)
+	}
+}
+
+func TestGetRelated(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	s := NewBlogService(&c, similarity.NewIndex(), DefaultDuplicatePolicy)
+
+	blogs := map[string]*models.Blog{
+		"1": {Body: "docker compose networking"},
+		"2": {Body: "kubernetes networking"},
+		"3": {Body: "sourdough bread"},
+	}
+	for id, blog := range blogs {
+		n, _ := strconv.Atoi(id)
+		blog.ID = uint(n)
+	}
+
+	store := &mocks.BlogRepositoryMock{
+		MockGetByID: func(id string) (*models.Blog, error) {
+			return blogs[id], nil
+		},
+		MockGetAll: func() ([]*models.Blog, error) {
+			return []*models.Blog{blogs["1"], blogs["2"], blogs["3"]}, nil
+		},
+	}
+	if err := s.IndexAll(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.GetRelated(ctx, "1", 5, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].ID != 2 {
+		t.Errorf("expected only blog 2 to be related, got %+v", res)
+	}
+}
+
+func TestCreateNearDuplicate(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	d := &dtos.CreateBlogRequest{
+		Title: "my first blog post, again",
+		Body:  "hello world! this is my very first blog post",
+	}
+	// The existing blog was written before blogs had a fingerprint.
+	existing := &models.Blog{Body: "Hello world, this is my very first blog post."}
+	existing.ID = 7
+	store := &mocks.BlogRepositoryMock{
+		MockGetAll: func() ([]*models.Blog, error) {
+			return []*models.Blog{existing}, nil
+		},
+	}
+
+	tests := [...]struct {
+		name      string
+		action    DuplicateAction
+		shouldErr bool
+	}{
+		{"Reject", DuplicateActionReject, true},
+		{"Warn", DuplicateActionWarn, false},
+		{"Ignore", DuplicateActionIgnore, false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewBlogService(&c, similarity.NewIndex(), DuplicatePolicy{Threshold: 0.9, Action: tt.action})
+			if err := s.IndexAll(ctx, store); err != nil {
+				t.Fatal(err)
+			}
+			_, err := s.Create(ctx, d, store)
+
+			var nearDuplicateErr *apiErrors.NearDuplicateError
+			if tt.shouldErr != errors.As(err, &nearDuplicateErr) {
+				t.Fatalf("expected near-duplicate error: %v, got %v", tt.shouldErr, err)
+			}
+			if tt.shouldErr && nearDuplicateErr.ConflictingID != 7 {
+				t.Errorf("expected conflicting id 7, got %d", nearDuplicateErr.ConflictingID)
+			}
+		})
+	}
+}
+
+// TestCreateWithoutWords enforces that bodies without words, which have no
+// fingerprint, are not near-duplicates of one another.
+func TestCreateWithoutWords(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	s := NewBlogService(&c, similarity.NewIndex(), DuplicatePolicy{Threshold: 0.9, Action: DuplicateActionReject})
+	store := repositories.NewMemoryBlogRepository()
+
+	for _, title := range []string{"dots", "more dots"} {
+		res, err := s.Create(ctx, &dtos.CreateBlogRequest{Title: title, Body: "..."}, store)
+		if err != nil {
+			t.Fatalf("expected %q to be created, got %v", title, err)
+		}
+		if res.Fingerprint != nil {
+			t.Errorf("expected no fingerprint, got %d", *res.Fingerprint)
+		}
+	}
+}
+
+// TestIndexAllBackfill enforces that the blogs written before blogs had a
+// fingerprint are given one, and that the others are left alone.
+func TestIndexAllBackfill(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	s := NewBlogService(&c, similarity.NewIndex(), DefaultDuplicatePolicy)
+
+	fingerprint := int64(42)
+	blogs := []*models.Blog{
+		{Body: "written before fingerprints"},
+		{Body: "written after fingerprints", Fingerprint: &fingerprint},
+		{Body: "..."},
+	}
+	for i, blog := range blogs {
+		blog.ID = uint(i + 1)
+	}
+
+	backfilled := map[uint]int64{}
+	store := &mocks.BlogRepositoryMock{
+		MockGetAll: func() ([]*models.Blog, error) {
+			return blogs, nil
+		},
+		MockSetFingerprint: func(id uint, fingerprint int64) error {
+			backfilled[id] = fingerprint
+			return nil
+		},
+	}
+	if err := s.IndexAll(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := similarity.SimHash(blogs[0].Body)
+	if len(backfilled) != 1 || backfilled[1] != int64(want) {
+		t.Errorf("expected only blog 1 to be backfilled with %d, got %v", int64(want), backfilled)
+	}
+	if _, score, _ := s.fingerprints.Closest(want, 0); score != 1 {
+		t.Errorf("expected the backfilled fingerprint to be checked for duplicates, got a score of %v", score)
+	}
 }
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+
+	apiErrors "example.com/m/v2/errors"
+	"example.com/m/v2/ioc"
+	repositories "example.com/m/v2/repositories"
+)
+
+// CRUDService implements the business logic shared by every resource: it maps
+// request DTOs to models, and refines the codes of repository errors with the
+// resource name. Resource-specific services embed it, and wrap the methods
+// that need more than that.
+type CRUDService[T any, CreateDTO any, UpdateDTO any] struct {
+	ioc       *ioc.IOC
+	resource  string
+	mapCreate func(CreateDTO) *T
+	mapUpdate func(UpdateDTO) *T
+}
+
+// NewCRUDService builds a service for the resource, e.g. "blog", whose DTOs
+// are converted to models with mapCreate and mapUpdate.
+func NewCRUDService[T any, CreateDTO any, UpdateDTO any](c *ioc.IOC, resource string, mapCreate func(CreateDTO) *T, mapUpdate func(UpdateDTO) *T) *CRUDService[T, CreateDTO, UpdateDTO] {
+	return &CRUDService[T, CreateDTO, UpdateDTO]{
+		ioc:       c,
+		resource:  resource,
+		mapCreate: mapCreate,
+		mapUpdate: mapUpdate,
+	}
+}
+
+func (s CRUDService[T, CreateDTO, UpdateDTO]) Create(ctx context.Context, m *CreateDTO, r repositories.Creator[T]) (*T, error) {
+	return s.CreateModel(ctx, s.mapCreate(*m), r)
+}
+
+// CreateModel stores a model that has already been mapped from its DTO.
+func (s CRUDService[T, CreateDTO, UpdateDTO]) CreateModel(ctx context.Context, m *T, r repositories.Creator[T]) (*T, error) {
+	res, err := r.Create(ctx, m)
+	if err != nil {
+		return nil, apiErrors.WithResource(s.resource, err)
+	}
+	return res, nil
+}
+
+func (s CRUDService[T, CreateDTO, UpdateDTO]) GetByID(ctx context.Context, id string, r repositories.Getter[T]) (*T, error) {
+	res, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, apiErrors.WithResource(s.resource, err)
+	}
+	return res, nil
+}
+
+func (s CRUDService[T, CreateDTO, UpdateDTO]) GetAll(ctx context.Context, r repositories.Lister[T]) ([]*T, error) {
+	res, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, apiErrors.WithResource(s.resource, err)
+	}
+	return res, nil
+}
+
+func (s CRUDService[T, CreateDTO, UpdateDTO]) Update(ctx context.Context, id uint, m *UpdateDTO, r repositories.Updater[T]) (*T, error) {
+	return s.UpdateModel(ctx, id, s.mapUpdate(*m), r)
+}
+
+// UpdateModel stores a model that has already been mapped from its DTO.
+func (s CRUDService[T, CreateDTO, UpdateDTO]) UpdateModel(ctx context.Context, id uint, m *T, r repositories.Updater[T]) (*T, error) {
+	res, err := r.Update(ctx, id, m)
+	if err != nil {
+		return nil, apiErrors.WithResource(s.resource, err)
+	}
+	return res, nil
+}
+
+func (s CRUDService[T, CreateDTO, UpdateDTO]) Delete(ctx context.Context, id string, r repositories.Deleter[T]) error {
+	if err := r.Delete(ctx, id); err != nil {
+		return apiErrors.WithResource(s.resource, err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	dtos "example.com/m/v2/dtos"
+	apiErrors "example.com/m/v2/errors"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	mocks "example.com/m/v2/repositories/mocks"
+)
+
+func TestCRUDService(t *testing.T) {
+	ctx := context.Background()
+	c := ioc.NewContainer()
+	s := NewCRUDService(&c, "blog",
+		func(d dtos.CreateBlogRequest) *models.Blog { return &models.Blog{Title: d.Title} },
+		func(d dtos.UpdateBlogRequest) *models.Blog { return &models.Blog{Title: d.Title} },
+	)
+
+	created, err := s.Create(ctx, &dtos.CreateBlogRequest{Title: "title"}, &mocks.BlogRepositoryMock{
+		MockCreate: func(m *models.Blog) (*models.Blog, error) {
+			return m, nil
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "title", created.Title, "The DTO is mapped with mapCreate")
+
+	store := &mocks.BlogRepositoryMock{
+		MockGetByID: func(id string) (*models.Blog, error) {
+			return nil, gorm.ErrRecordNotFound
+		},
+	}
+	_, err = s.GetByID(ctx, "1", store)
+	result := apiErrors.NewAPIError(err)
+	assert.Equal(t, apiErrors.CodeBlogNotFound, result.GetErrorCode(), "Errors are refined with the resource")
+}
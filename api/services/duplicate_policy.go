@@ -0,0 +1,33 @@
+package services
+
+import (
+	"example.com/m/v2/config"
+)
+
+// DuplicateAction is what happens when a blog is a near-duplicate of another.
+type DuplicateAction string
+
+const (
+	DuplicateActionIgnore DuplicateAction = "ignore"
+	DuplicateActionWarn   DuplicateAction = "warn"
+	DuplicateActionReject DuplicateAction = "reject"
+)
+
+// DuplicatePolicy decides when a blog counts as a near-duplicate of an
+// existing one, and what to do about it. Threshold is the SimHash similarity,
+// from 0 to 1, at or above which two blogs are considered near-duplicates.
+type DuplicatePolicy struct {
+	Threshold float64
+	Action    DuplicateAction
+}
+
+var DefaultDuplicatePolicy = NewDuplicatePolicy(config.Default().Duplicates)
+
+// NewDuplicatePolicy returns the policy configured by config, which is
+// validated when it is loaded.
+func NewDuplicatePolicy(config config.Duplicates) DuplicatePolicy {
+	return DuplicatePolicy{
+		Threshold: config.Threshold,
+		Action:    DuplicateAction(config.Action),
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+
+	dtos "example.com/m/v2/dtos"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	repositories "example.com/m/v2/repositories"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedBlogService starts a span for every call to the BlogService that it
+// wraps, e.g. `BlogService.Create`, so that traces show the time spent in the
+// business logic apart from the queries that it runs.
+type tracedBlogService struct {
+	BlogService
+	tracer trace.Tracer
+}
+
+// NewTracedBlogService wraps s with spans from the tracer provider of c.
+func NewTracedBlogService(c *ioc.IOC, s BlogService) BlogService {
+	return tracedBlogService{
+		BlogService: s,
+		tracer:      c.Tracing.Tracer("example.com/m/v2/services"),
+	}
+}
+
+func (s tracedBlogService) Create(ctx context.Context, m *dtos.CreateBlogRequest, r repositories.BlogCreator) (*models.Blog, error) {
+	ctx, span := s.start(ctx, "Create")
+	res, err := s.BlogService.Create(ctx, m, r)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("blog.id", int64(res.ID)))
+	}
+	return res, endSpan(span, err)
+}
+
+func (s tracedBlogService) GetByID(ctx context.Context, id string, r repositories.SingleBlogGetter) (*models.Blog, error) {
+	ctx, span := s.start(ctx, "GetByID", attribute.String("blog.id", id))
+	res, err := s.BlogService.GetByID(ctx, id, r)
+	return res, endSpan(span, err)
+}
+
+func (s tracedBlogService) GetAll(ctx context.Context, r repositories.MultiBlogGetter) ([]*models.Blog, error) {
+	ctx, span := s.start(ctx, "GetAll")
+	res, err := s.BlogService.GetAll(ctx, r)
+	span.SetAttributes(attribute.Int("blog.count", len(res)))
+	return res, endSpan(span, err)
+}
+
+func (s tracedBlogService) Update(ctx context.Context, id uint, m *dtos.UpdateBlogRequest, r repositories.BlogUpdater) (*models.Blog, error) {
+	ctx, span := s.start(ctx, "Update", attribute.Int64("blog.id", int64(id)))
+	res, err := s.BlogService.Update(ctx, id, m, r)
+	return res, endSpan(span, err)
+}
+
+func (s tracedBlogService) Delete(ctx context.Context, id string, r repositories.BlogDeleter) error {
+	ctx, span := s.start(ctx, "Delete", attribute.String("blog.id", id))
+	return endSpan(span, s.BlogService.Delete(ctx, id, r))
+}
+
+func (s tracedBlogService) GetRelated(ctx context.Context, id string, limit int, r repositories.RelatedBlogGetter) ([]*dtos.RelatedBlogResponse, error) {
+	ctx, span := s.start(ctx, "GetRelated", attribute.String("blog.id", id), attribute.Int("limit", limit))
+	res, err := s.BlogService.GetRelated(ctx, id, limit, r)
+	return res, endSpan(span, err)
+}
+
+func (s tracedBlogService) IndexAll(ctx context.Context, r repositories.BlogIndexer) error {
+	ctx, span := s.start(ctx, "IndexAll")
+	return endSpan(span, s.BlogService.IndexAll(ctx, r))
+}
+
+func (s tracedBlogService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "BlogService."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span, marking it as failed with err, if any, and returns
+// err.
+func endSpan(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	return err
+}
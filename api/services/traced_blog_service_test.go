@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dtos "example.com/m/v2/dtos"
+	"example.com/m/v2/ioc"
+	models "example.com/m/v2/models"
+	similarity "example.com/m/v2/pkg/similarity"
+	mocks "example.com/m/v2/repositories/mocks"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedBlogService(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	c := ioc.NewContainer()
+	c.Tracing = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	s := NewTracedBlogService(&c, NewBlogService(&c, similarity.NewIndex(), DefaultDuplicatePolicy))
+
+	ctx, parent := c.Tracing.Tracer("test").Start(context.Background(), "GET /blogs/:id")
+	_, _ = s.GetByID(ctx, "1", &mocks.BlogRepositoryMock{})
+	_, err := s.Create(ctx, &dtos.CreateBlogRequest{Title: "title", Body: "body"}, &mocks.BlogRepositoryMock{
+		MockCreate: func(m *models.Blog) (*models.Blog, error) {
+			return nil, errors.New("connection refused")
+		},
+	})
+	parent.End()
+
+	if err == nil {
+		t.Fatal("expected the error of the repository but got <nil>")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	for i, name := range []string{"BlogService.GetByID", "BlogService.Create"} {
+		span := spans[i]
+		if span.Name() != name {
+			t.Errorf("expected span %q, got %q", name, span.Name())
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected %s to be a child of the request span", name)
+		}
+	}
+	if spans[0].Status().Code != codes.Unset {
+		t.Errorf("expected GetByID to succeed, got %v", spans[0].Status())
+	}
+	if spans[1].Status().Code != codes.Error {
+		t.Errorf("expected Create to fail, got %v", spans[1].Status())
+	}
+}
@@ -0,0 +1,137 @@
+// Package tracing exports the traces of the server, as configured by
+// `tracing`. Components start their spans from the tracer provider of the IOC,
+// which discards them until Setup is called.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	ioc "example.com/m/v2/ioc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// Exporters of traces, selected by `tracing.exporter`.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// otlpTracesPath is appended to `tracing.endpoint`, like the OTLP exporters
+// do with OTEL_EXPORTER_OTLP_ENDPOINT.
+const otlpTracesPath = "/v1/traces"
+
+// Setup replaces the tracer provider of c with one that exports to
+// `tracing.exporter`, unless that is "none". It must be called before the
+// components that trace are built, as they keep the tracers that they get.
+//
+// Spans are exported in batches. The provider is appended to the lifecycle of
+// c, so that it is stopped last, and flushes the spans of the others.
+func Setup(c *ioc.IOC) error {
+	config := c.Config.Tracing
+	if config.Exporter == ExporterNone {
+		return nil
+	}
+
+	exporter, closer, err := newExporter(config.Exporter, config.Endpoint, config.File)
+	if err != nil {
+		return fmt.Errorf("tracing: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.ServiceName))),
+		// Follow the decision of the caller, if it sent a `traceparent`.
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	c.Tracing = provider
+	c.Lifecycle.Append(ioc.Hook{
+		Name: "tracer provider",
+		OnStop: func(ctx context.Context) error {
+			err := provider.Shutdown(ctx)
+			if closer != nil {
+				if closeErr := closer.Close(); err == nil {
+					err = closeErr
+				}
+			}
+			return err
+		},
+	})
+
+	c.Logger.Info("Exporting traces to", destination(config.Exporter, config.Endpoint, config.File))
+	return nil
+}
+
+// newExporter returns the exporter, and the file that it writes to, if any,
+// which must be closed after the exporter is shut down.
+func newExporter(exporter string, endpoint string, file string) (sdktrace.SpanExporter, io.Closer, error) {
+	switch exporter {
+	case ExporterOTLP:
+		opts, err := otlpOptions(endpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+		e, err := otlptracehttp.New(context.Background(), opts...)
+		return e, nil, err
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		return e, nil, err
+	case ExporterFile:
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		// One span per line, which is easy to grep.
+		e, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return e, f, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown exporter %q", exporter)
+	}
+}
+
+// otlpOptions points the OTLP exporter at endpoint, a base URL such as
+// `http://collector:4318`. Without endpoint, the exporter falls back to its
+// defaults and the `OTEL_EXPORTER_OTLP_*` variables.
+func otlpOptions(endpoint string) ([]otlptracehttp.Option, error) {
+	if endpoint == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("tracing.endpoint must be an http:// or https:// URL, got %q", endpoint)
+	}
+	opts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(u.Path, "/") + otlpTracesPath),
+	}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	return opts, nil
+}
+
+// destination describes where the exporter sends traces, for the logs.
+func destination(exporter string, endpoint string, file string) string {
+	switch {
+	case exporter == ExporterOTLP && endpoint != "":
+		return endpoint
+	case exporter == ExporterFile:
+		return file
+	}
+	return exporter
+}
@@ -0,0 +1,32 @@
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTLPOptions(t *testing.T) {
+	tests := [...]struct {
+		endpoint string
+		options  int
+		valid    bool
+	}{
+		{"", 0, true},
+		{"http://collector:4318", 3, true},
+		{"https://collector.example.com/otlp/", 2, true},
+		{"collector:4318", 0, false},
+		{"grpc://collector:4317", 0, false},
+		{"http://", 0, false},
+		{"http://collector:4318/%zz", 0, false},
+	}
+	for _, tt := range tests {
+		opts, err := otlpOptions(tt.endpoint)
+		if tt.valid {
+			assert.NoError(t, err, tt.endpoint)
+		} else {
+			assert.ErrorContains(t, err, "tracing.endpoint", tt.endpoint)
+		}
+		assert.Len(t, opts, tt.options, "%s: plain http is insecure", tt.endpoint)
+	}
+}
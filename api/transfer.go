@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	app "example.com/m/v2/app"
+	dtos "example.com/m/v2/dtos"
+	fixtures "example.com/m/v2/fixtures"
+	ioc "example.com/m/v2/ioc"
+)
+
+// setupExport implements `server export`, which writes every blog as a JSON
+// array, in the format that `server import` reads.
+func setupExport(flags *flag.FlagSet) runFunc {
+	path := flags.String("o", "", "file to write to, instead of STDOUT")
+
+	return func(c *ioc.IOC, args []string) error {
+		a, err := app.New(c, app.Options{})
+		if err != nil {
+			return err
+		}
+
+		w := io.Writer(os.Stdout)
+		if *path != "" {
+			f, err := os.Create(*path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		return export(a, w)
+	}
+}
+
+// export writes every blog to w.
+func export(a *app.App, w io.Writer) error {
+	blogs, err := a.BlogService.GetAll(context.Background(), a.BlogRepository)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(blogs); err != nil {
+		return err
+	}
+	a.IOC.Logger.Info("Exported", len(blogs), "blogs")
+	return nil
+}
+
+// setupImport implements `server import`, which creates the blogs of a JSON
+// array, such as one written by `server export`. Only their titles and bodies
+// are imported; the blogs are given new IDs. Blogs whose title is taken are
+// skipped, so that importing is idempotent.
+func setupImport(flags *flag.FlagSet) runFunc {
+	path := flags.String("i", "", "file to read from, instead of STDIN")
+
+	return func(c *ioc.IOC, args []string) error {
+		r := io.Reader(os.Stdin)
+		if *path != "" {
+			f, err := os.Open(*path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		set, err := decode(r)
+		if err != nil {
+			return err
+		}
+
+		a, err := app.New(c, app.Options{})
+		if err != nil {
+			return err
+		}
+		return load(a, set)
+	}
+}
+
+// decode reads the blogs written by export.
+func decode(r io.Reader) (*fixtures.Set, error) {
+	var requests []dtos.CreateBlogRequest
+	if err := json.NewDecoder(r).Decode(&requests); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of blogs: %w", err)
+	}
+	return &fixtures.Set{Blogs: requests}, nil
+}
+
+// load creates the blogs of the set, unless their title is taken, and fails if
+// any of them could not be created.
+func load(a *app.App, set *fixtures.Set) error {
+	// The blogs that already exist are indexed first, so that the new ones
+	// are checked against them for near duplicates.
+	if err := a.BlogService.IndexAll(context.Background(), a.BlogRepository); err != nil {
+		return err
+	}
+
+	res, err := fixtures.Load(context.Background(), a.IOC, a.BlogService, a.BlogRepository, set)
+	if err != nil {
+		return err
+	}
+
+	a.IOC.Logger.Info("Created", res.Created, "blogs; skipped", res.Skipped, "that already exist")
+	if res.Failed > 0 {
+		return fmt.Errorf("failed to create %d of %d blogs", res.Failed, len(set.Blogs))
+	}
+	return nil
+}